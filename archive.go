@@ -0,0 +1,227 @@
+package stream
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+)
+
+// ErrEntryPassed is returned by reading a tar entry once the stream has
+// moved on to the next one.
+var ErrEntryPassed = errors.New("stream: tar entry read after the next one")
+
+// ArchiveEntry is a file of a zip or tar archive.
+type ArchiveEntry struct {
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+	// Header is the *zip.FileHeader or *tar.Header of the entry.
+	Header any
+	open   func() (io.ReadCloser, error)
+}
+
+// Open reads the content of the entry. A zip entry can be opened at any
+// time, a tar entry only until the stream moves on to the next one, so
+// before any stage that holds on to entries, such as Sort, or going
+// parallel.
+func (e ArchiveEntry) Open() (io.ReadCloser, error) {
+	return e.open()
+}
+
+// FromZip yields the entries of the zip archive of size bytes read from r,
+// in the order of its directory. An archive that cannot be read yields
+// nothing and is reported by Err.
+func FromZip(r io.ReaderAt, size int64) Stream[ArchiveEntry] {
+	zr, err := zip.NewReader(r, size)
+	var list []ArchiveEntry
+	if err == nil {
+		list = make([]ArchiveEntry, len(zr.File))
+		for i, f := range zr.File {
+			list[i] = ArchiveEntry{
+				Name:    f.Name,
+				Size:    int64(f.UncompressedSize64),
+				Mode:    f.Mode(),
+				ModTime: f.Modified,
+				Header:  &f.FileHeader,
+				open:    f.Open,
+			}
+		}
+	}
+	return Builder[ArchiveEntry]().iterator(&errIterator[ArchiveEntry]{Iterator: collections.IterableSlice(list...), err: err}).Build()
+}
+
+// tarIterator reads the headers of a tar archive one at a time.
+type tarIterator struct {
+	reader *tar.Reader
+	closer io.Closer
+	// read counts the headers read, an entry is current while it is the last
+	read  int
+	next  ArchiveEntry
+	ready bool
+	done  bool
+	err   error
+}
+
+func (it *tarIterator) HasNext() bool {
+	if it.ready || it.done {
+		return it.ready
+	}
+	hdr, err := it.reader.Next()
+	it.read++
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+		}
+		it.done = true
+		it.Close()
+		return false
+	}
+	read := it.read
+	it.next = ArchiveEntry{
+		Name:    hdr.Name,
+		Size:    hdr.Size,
+		Mode:    hdr.FileInfo().Mode(),
+		ModTime: hdr.ModTime,
+		Header:  hdr,
+		open: func() (io.ReadCloser, error) {
+			if it.read != read {
+				return nil, ErrEntryPassed
+			}
+			return io.NopCloser(&tarEntryReader{it: it, read: read}), nil
+		},
+	}
+	it.ready = true
+	return true
+}
+
+func (it *tarIterator) Next() ArchiveEntry {
+	var e ArchiveEntry
+	if it.HasNext() {
+		e, it.ready = it.next, false
+	}
+	return e
+}
+
+func (it *tarIterator) ForEachRemaining(fn function.Consumer[ArchiveEntry]) {
+	for it.HasNext() {
+		fn(it.Next())
+	}
+}
+
+func (it *tarIterator) Err() error {
+	return it.err
+}
+
+// Close releases the decompressor of a gzip compressed archive.
+func (it *tarIterator) Close() error {
+	if it.closer == nil {
+		return nil
+	}
+	closer := it.closer
+	it.closer = nil
+	return closer.Close()
+}
+
+// tarEntryReader reads the content of the current entry only.
+type tarEntryReader struct {
+	it   *tarIterator
+	read int
+}
+
+func (r *tarEntryReader) Read(p []byte) (int, error) {
+	if r.it.read != r.read {
+		return 0, ErrEntryPassed
+	}
+	return r.it.reader.Read(p)
+}
+
+// FromTar yields the entries of the tar archive read from r, gzip
+// compressed or not, without reading ahead of the stream. A malformed
+// archive ends the stream and is reported by Err.
+func FromTar(r io.Reader) Stream[ArchiveEntry] {
+	it := &tarIterator{}
+	rc, err := gunzip(r)
+	if err != nil {
+		it.err, it.done = err, true
+	} else {
+		it.reader, it.closer = tar.NewReader(rc), rc
+	}
+	return Builder[ArchiveEntry]().iterator(it).Build()
+}
+
+// ArchiveFile is a named payload ToZip and ToTar write into an archive.
+type ArchiveFile struct {
+	Name string
+	Data []byte
+	// Mode is the permission of the file, 0644 when zero.
+	Mode fs.FileMode
+	// ModTime is the modification time of the file, now when zero.
+	ModTime time.Time
+}
+
+func (f ArchiveFile) mode() fs.FileMode {
+	if f.Mode == 0 {
+		return 0o644
+	}
+	return f.Mode
+}
+
+func (f ArchiveFile) modTime() time.Time {
+	if f.ModTime.IsZero() {
+		return time.Now()
+	}
+	return f.ModTime
+}
+
+// ToZip writes the files of s to w as a deflated zip archive. A parallel s
+// writes its files out of encounter order. It returns the first error of
+// writing or of s.
+func ToZip(s Stream[ArchiveFile], w io.Writer) error {
+	zw := zip.NewWriter(w)
+	err := forEachErr(s, func(f ArchiveFile) error {
+		hdr := &zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: f.modTime()}
+		hdr.SetMode(f.mode())
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(f.Data)
+		return err
+	})
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ToTar writes the files of s to w as a tar archive, wrap w in a
+// gzip.Writer to compress it. A parallel s writes its files out of
+// encounter order. It returns the first error of writing or of s.
+func ToTar(s Stream[ArchiveFile], w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := forEachErr(s, func(f ArchiveFile) error {
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     f.Name,
+			Size:     int64(len(f.Data)),
+			Mode:     int64(f.mode().Perm()),
+			ModTime:  f.modTime(),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(f.Data)
+		return err
+	})
+	if closeErr := tw.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
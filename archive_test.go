@@ -0,0 +1,122 @@
+package stream_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func archiveFiles() []stream.ArchiveFile {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	return []stream.ArchiveFile{
+		{Name: "bin/tool", Data: []byte("#!/bin/sh\n"), Mode: 0o755, ModTime: at},
+		{Name: "README.md", Data: []byte("# tool\n"), ModTime: at},
+		{Name: "empty", ModTime: at},
+	}
+}
+
+// contents reads the content of every entry as it streams past.
+func contents(t *testing.T, s stream.Stream[stream.ArchiveEntry]) map[string]string {
+	files := make(map[string]string)
+	s.ForEach(func(e stream.ArchiveEntry) {
+		rc, err := e.Open()
+		assert.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+		assert.Equal(t, e.Size, int64(len(b)))
+		files[e.Name] = string(b)
+	})
+	return files
+}
+
+func TestZip(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, stream.ToZip(stream.From(archiveFiles()...), &buf))
+	want := map[string]string{"bin/tool": "#!/bin/sh\n", "README.md": "# tool\n", "empty": ""}
+
+	t.Run("entries", func(t *testing.T) {
+		s := stream.FromZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		entries := s.ToSlice()
+		assert.NoError(t, s.Err())
+		assert.Len(t, entries, 3)
+		assert.Equal(t, "bin/tool", entries[0].Name)
+		assert.Equal(t, "-rwxr-xr-x", entries[0].Mode.String())
+		assert.Equal(t, "-rw-r--r--", entries[1].Mode.String())
+		assert.True(t, entries[0].ModTime.Equal(archiveFiles()[0].ModTime))
+		assert.IsType(t, &zip.FileHeader{}, entries[0].Header)
+	})
+	t.Run("content", func(t *testing.T) {
+		s := stream.FromZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.Equal(t, want, contents(t, s.Sort(func(a, b stream.ArchiveEntry) bool { return a.Name < b.Name })))
+	})
+	t.Run("parallel", func(t *testing.T) {
+		s := stream.FromZip(bytes.NewReader(buf.Bytes()), int64(buf.Len())).Parallel().
+			MapToString(func(e stream.ArchiveEntry) string {
+				rc, err := e.Open()
+				assert.NoError(t, err)
+				defer rc.Close()
+				b, err := io.ReadAll(rc)
+				assert.NoError(t, err)
+				return string(b)
+			})
+		assert.Equal(t, []string{"#!/bin/sh\n", "# tool\n", ""}, s.ToSlice())
+	})
+	t.Run("not a zip", func(t *testing.T) {
+		s := stream.FromZip(strings.NewReader("nope"), 4)
+		assert.Empty(t, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), zip.ErrFormat)
+	})
+}
+
+func TestTar(t *testing.T) {
+	var plain bytes.Buffer
+	assert.NoError(t, stream.ToTar(stream.From(archiveFiles()...), &plain))
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	assert.NoError(t, stream.ToTar(stream.From(archiveFiles()...), zw))
+	assert.NoError(t, zw.Close())
+	want := map[string]string{"bin/tool": "#!/bin/sh\n", "README.md": "# tool\n", "empty": ""}
+
+	t.Run("content", func(t *testing.T) {
+		for name, buf := range map[string][]byte{"plain": plain.Bytes(), "gzip": compressed.Bytes()} {
+			s := stream.FromTar(bytes.NewReader(buf))
+			assert.Equal(t, want, contents(t, s), name)
+			assert.NoError(t, s.Err(), name)
+		}
+	})
+	t.Run("entries", func(t *testing.T) {
+		entries := stream.FromTar(bytes.NewReader(plain.Bytes())).ToSlice()
+		assert.Len(t, entries, 3)
+		assert.Equal(t, "-rwxr-xr-x", entries[0].Mode.String())
+		assert.Equal(t, int64(10), entries[0].Size)
+		assert.IsType(t, &tar.Header{}, entries[0].Header)
+
+		_, err := entries[0].Open()
+		assert.ErrorIs(t, err, stream.ErrEntryPassed)
+	})
+	t.Run("read after next", func(t *testing.T) {
+		s := stream.FromTar(bytes.NewReader(plain.Bytes()))
+		var first io.ReadCloser
+		s.ForEach(func(e stream.ArchiveEntry) {
+			if first == nil {
+				first, _ = e.Open()
+			}
+		})
+		_, err := io.ReadAll(first)
+		assert.ErrorIs(t, err, stream.ErrEntryPassed)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		s := stream.FromTar(bytes.NewReader(plain.Bytes()[:1100]))
+		assert.Len(t, s.ToSlice(), 1)
+		assert.Error(t, s.Err())
+	})
+}
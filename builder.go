@@ -2,38 +2,95 @@ package stream
 
 import (
 	"context"
+	"io"
 
 	"github.com/go-park/stream/support/collections"
-	"github.com/go-park/stream/support/function"
 	"github.com/go-park/stream/support/routine"
 	"golang.org/x/exp/constraints"
 )
 
+// DefaultBuffer is the read ahead of parallel streams over channels.
+const DefaultBuffer = 1024
+
 func Builder[T any]() builder[T] {
 	return builder[T]{}
 }
 
 type builder[T any] struct {
-	iter     collections.Iterator[T]
+	iter collections.Iterator[T]
+	// open makes the source of a pipeline bound to a context, it replaces iter
+	open     func(ctx context.Context) collections.Iterator[T]
+	ctx      context.Context
+	buffer   int
 	reusable bool
 	parallel bool
+	observer Observer
 }
 
 func (b builder[T]) Source(t ...T) builder[T] {
-	b.iter = collections.IterableSlice(t...)
-	return b
+	return b.iterator(collections.IterableSlice(t...))
 }
 
 func (b builder[T]) iterator(iter collections.Iterator[T]) builder[T] {
-	b.iter = iter
+	b.iter, b.open = iter, nil
+	return b
+}
+
+// Chan receives the elements from ch until it is closed, ctx is done or
+// the stream is closed. A parallel stream reads DefaultBuffer elements
+// ahead of its workers unless Buffer says otherwise.
+func (b builder[T]) Chan(ctx context.Context, ch <-chan T) builder[T] {
+	b.iter, b.ctx = nil, ctx
+	b.open = func(ctx context.Context) collections.Iterator[T] {
+		return collections.IterableChanContext(ctx, ch)
+	}
+	if b.buffer == 0 {
+		b.buffer = DefaultBuffer
+	}
+	return b
+}
+
+// Buffer bounds the number of elements a parallel stream reads ahead of its
+// workers from a source of unknown size, 0 reads it all at once. The
+// producer of a full buffer waits for the workers instead of piling up.
+func (b builder[T]) Buffer(n int) builder[T] {
+	b.buffer = n
 	return b
 }
 
+// source binds the source of the builder to a new context, cancelled when
+// the stream is closed.
+func (b builder[T]) source() (context.Context, context.CancelFunc, collections.Iterator[T]) {
+	parent := b.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancelFn := context.WithCancel(parent)
+	if b.open != nil {
+		return ctx, cancelFn, b.open(ctx)
+	}
+	return ctx, cancelFn, b.iter
+}
+
 func (b builder[T]) Parallel() builder[T] {
 	b.parallel = true
 	return b
 }
 
+// Observe attaches observers notified of every stage the pipeline runs.
+// Only the default engine is observed.
+func (b builder[T]) Observe(observers ...Observer) builder[T] {
+	switch len(observers) {
+	case 0:
+		b.observer = nil
+	case 1:
+		b.observer = observers[0]
+	default:
+		b.observer = multiObserver(observers)
+	}
+	return b
+}
+
 func (b builder[T]) Simple() Stream[T] {
 	return b.buildSimple()
 }
@@ -44,53 +101,62 @@ func (b builder[T]) Build() Stream[T] {
 
 func (b builder[T]) buildSimple() SimplePipline[T] {
 	target := make(chan T)
-	ctx, cancelFn := context.WithCancel(context.Background())
+	ctx, cancelFn, iter := b.source()
 	routine.Run(func() {
 		defer cancelFn()
 		defer close(target)
-		for b.iter.HasNext() {
+		if c, ok := iter.(io.Closer); ok {
+			defer c.Close()
+		}
+		for iter.HasNext() {
 			select {
 			case <-ctx.Done():
 				return
-			default:
-				target <- b.iter.Next()
+			case target <- iter.Next():
 			}
 		}
 	})
-	return SimplePipline[T]{upstream: target, cancel: cancelFn, parallel: b.parallel}
+	var err func() error
+	if f, ok := iter.(interface{ Err() error }); ok {
+		err = f.Err
+	}
+	return SimplePipline[T]{upstream: target, ctx: ctx, cancel: cancelFn, parallel: b.parallel, err: err}
 }
 
 func (b builder[T]) buildFast() Stream[T] {
-	_, cancelFn := context.WithCancel(context.Background())
-	return &FastPipline[T]{source: b.iter, opWrapper: defultOpWrapper[T], cancel: cancelFn}
+	ctx, cancelFn, iter := b.source()
+	return &FastPipline[T]{source: iter, ctx: ctx, cancel: cancelFn, buffer: b.buffer, observer: b.observer}
 }
 
-func defultOpWrapper[T any](down function.Consumer[T]) function.Consumer[T] {
-	return func(t T) {
-		down.Accept(t)
-	}
+func FromMap[M ~map[K]V, K comparable, V any](m M) Stream[collections.Entry[K, V]] {
+	return Builder[collections.Entry[K, V]]().iterator(collections.IterableMap(m)).Build()
 }
 
-func FromMap[M ~map[K]V, K comparable, V any](m M) Stream[collections.Entry[K, V]] {
-	return From(collections.GetEntrySet(m)...)
+// FromChan receives the elements from ch until it is closed or ctx is done.
+func FromChan[T any](ctx context.Context, ch <-chan T) Stream[T] {
+	return Builder[T]().Chan(ctx, ch).Build()
 }
 
 func From[T any](list ...T) Stream[T] {
 	return Builder[T]().Source(list...).Build()
 }
 
+// Range iterates from start to end inclusive.
 func Range[T constraints.Integer](start, end T) Stream[T] {
-	var iter collections.Iterator[T] = collections.Iterable(
-		func() (func() bool, function.Supplier[T]) {
-			hasNext := func() bool {
-				return start <= end
-			}
-			next := func() T {
-				cur := start
-				start++
-				return cur
-			}
-			return hasNext, next
-		})
-	return Builder[T]().iterator(iter).Build()
+	return RangeStep(start, end, 1)
+}
+
+// RangeStep iterates from start to end inclusive by step, a negative step counts down.
+func RangeStep[T constraints.Integer](start, end, step T) Stream[T] {
+	return Builder[T]().iterator(collections.IterableRange(start, end, step, true)).Build()
+}
+
+// RangeStepExclusive is RangeStep stopping before end.
+func RangeStepExclusive[T constraints.Integer](start, end, step T) Stream[T] {
+	return Builder[T]().iterator(collections.IterableRange(start, end, step, false)).Build()
+}
+
+// FloatRange yields count evenly spaced values from start to end inclusive.
+func FloatRange[T constraints.Float](start, end T, count int) Stream[T] {
+	return Builder[T]().iterator(collections.IterableFloatRange(start, end, count)).Build()
 }
@@ -1,9 +1,14 @@
 package stream_test
 
 import (
+	"context"
+	"math"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/go-park/stream"
+	"github.com/go-park/stream/support/collections"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,3 +63,123 @@ func TestRange(t *testing.T) {
 		assert.Equal(t, stream.Range(0, 99).ToSlice(), slice)
 	})
 }
+
+func TestRangeStep(t *testing.T) {
+	t.Run("step", func(t *testing.T) {
+		assert.Equal(t, []int{0, 3, 6, 9}, stream.RangeStep(0, 10, 3).ToSlice())
+		assert.Equal(t, []int{0, 3, 6, 9}, stream.RangeStep(0, 9, 3).ToSlice())
+		assert.Equal(t, []int{0, 3, 6}, stream.RangeStepExclusive(0, 9, 3).ToSlice())
+		assert.Equal(t, 0, len(stream.RangeStepExclusive(1, 1, 1).ToSlice()))
+		assert.Equal(t, 0, len(stream.RangeStep(2, 1, 1).ToSlice()))
+	})
+
+	t.Run("descending", func(t *testing.T) {
+		assert.Equal(t, []int{10, 8, 6, 4, 2, 0}, stream.RangeStep(10, 0, -2).ToSlice())
+		assert.Equal(t, []int{10, 8, 6, 4, 2}, stream.RangeStepExclusive(10, 0, -2).ToSlice())
+		assert.Equal(t, 0, len(stream.RangeStep(0, 10, -1).ToSlice()))
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		assert.Equal(t, []int8{125, 126, 127}, stream.Range[int8](125, math.MaxInt8).ToSlice())
+		assert.Equal(t, []uint8{254, 255}, stream.Range[uint8](254, math.MaxUint8).ToSlice())
+		assert.Equal(t, 256, stream.Range[int8](math.MinInt8, math.MaxInt8).Count())
+		assert.Equal(t, []int8{math.MinInt8, -1, 126}, stream.RangeStep[int8](math.MinInt8, math.MaxInt8, 127).ToSlice())
+	})
+
+	t.Run("sized", func(t *testing.T) {
+		mapped := 0
+		s := stream.Range(1, 1000000).Map(func(i int) int {
+			mapped++
+			return i
+		})
+		// the size of the source is the count, Map is not run
+		assert.Equal(t, 1000000, s.Count())
+		assert.Equal(t, 0, mapped)
+		compared := 0
+		sorted := stream.From(3, 1, 2).Map(func(i int) int {
+			mapped++
+			return i
+		}).Sort(func(i, j int) bool {
+			compared++
+			return i < j
+		})
+		assert.Equal(t, 3, sorted.Count())
+		assert.Equal(t, 0, mapped+compared)
+		filtered := stream.From(3, 1, 2).Map(func(i int) int {
+			mapped++
+			return i
+		}).Filter(func(i int) bool { return true })
+		assert.Equal(t, 3, filtered.Count())
+		assert.Equal(t, 3, mapped)
+		assert.Equal(t, 500000, stream.Range(1, 1000000).Filter(func(i int) bool { return i%2 == 0 }).Count())
+		// too many elements for an int, the source is not SIZED and Count iterates
+		huge := stream.Range[uint64](0, math.MaxUint64)
+		assert.False(t, huge.Explain().Source.Has(collections.SIZED))
+		assert.False(t, stream.Range[uint64](0, math.MaxInt).Explain().Source.Has(collections.SIZED))
+		assert.Equal(t, math.MaxInt, stream.Range[uint64](0, math.MaxInt-1).Count())
+		assert.Equal(t, 3, huge.Limit(3).Count())
+	})
+
+	t.Run("float", func(t *testing.T) {
+		assert.Equal(t, []float64{0, 0.25, 0.5, 0.75, 1}, stream.FloatRange(0.0, 1.0, 5).ToSlice())
+		list := stream.FloatRange(0.0, 1.0, 11).ToSlice()
+		assert.Equal(t, 11, len(list))
+		assert.Equal(t, 0.3, list[3])
+		assert.Equal(t, 1.0, list[10])
+		assert.Equal(t, []float32{2}, stream.FloatRange[float32](2, 3, 1).ToSlice())
+		assert.Equal(t, 0, stream.FloatRange(0.0, 1.0, 0).Count())
+	})
+}
+
+func TestFromChan(t *testing.T) {
+	produce := func(n int) chan int {
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			for i := 1; i <= n; i++ {
+				ch <- i
+			}
+		}()
+		return ch
+	}
+	t.Run("until closed", func(t *testing.T) {
+		assert.Equal(t, 5050, stream.Sum(stream.FromChan(context.Background(), produce(100))))
+		list := stream.FromChan(context.Background(), produce(100)).Parallel().ToSlice()
+		assert.Equal(t, stream.Range(1, 100).ToSlice(), list)
+	})
+	t.Run("until cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan int)
+		go func() {
+			ch <- 1
+			ch <- 2
+			cancel()
+		}()
+		assert.Equal(t, []int{1, 2}, stream.FromChan(ctx, ch).ToSlice())
+	})
+	t.Run("until closed stream", func(t *testing.T) {
+		s := stream.FromChan(context.Background(), make(chan int))
+		time.AfterFunc(10*time.Millisecond, s.Close)
+		assert.Empty(t, s.ToSlice())
+	})
+	t.Run("bounded read ahead", func(t *testing.T) {
+		var sent, seen, ahead int64
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			for i := 0; i < 200; i++ {
+				ch <- i
+				if d := atomic.AddInt64(&sent, 1) - atomic.LoadInt64(&seen); d > atomic.LoadInt64(&ahead) {
+					atomic.StoreInt64(&ahead, d)
+				}
+			}
+		}()
+		count := stream.Builder[int]().Chan(context.Background(), ch).Buffer(8).Build().Parallel().
+			Filter(func(int) bool {
+				atomic.AddInt64(&seen, 1)
+				return true
+			}).Count()
+		assert.Equal(t, 200, count)
+		assert.LessOrEqual(t, atomic.LoadInt64(&ahead), int64(8+1))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/go-park/stream/internal/helper"
 	"github.com/go-park/stream/support/function"
 	"github.com/go-park/stream/support/optional"
+	"github.com/go-park/stream/support/routine"
 	"golang.org/x/exp/constraints"
 )
 
@@ -29,17 +30,47 @@ func ToMap[T, V any, R comparable](s Stream[T], source function.Func[T, R], afte
 	return hash
 }
 
-func Distinct[T comparable](s Stream[T]) Stream[T] {
+// ToChan runs s in the background, sending its elements into the returned
+// channel which is closed once s is exhausted. Sends block while the
+// channel holds buffer elements, closing s stops it early. A parallel s
+// sends from its workers, out of encounter order.
+func ToChan[T any](s Stream[T], buffer int) <-chan T {
 	helper.RequireCanButNonNil(s)
-	s = s.Distinct(func(t, u T) bool {
-		return t == u
+	ch := make(chan T, buffer)
+	var done <-chan struct{}
+	if c, ok := s.(interface{ done() <-chan struct{} }); ok {
+		done = c.done()
+	}
+	routine.Run(func() {
+		defer close(ch)
+		s.ForEach(func(t T) {
+			select {
+			case ch <- t:
+			case <-done:
+			}
+		})
 	})
-	return s
+	return ch
+}
+
+// Distinct is skipped on a pipeline whose source is known DISTINCT.
+func Distinct[T comparable](s Stream[T]) Stream[T] {
+	helper.RequireCanButNonNil(s)
+	equals := func(t, u T) bool { return t == u }
+	if p, ok := s.(*FastPipline[T]); ok {
+		return p.distinct(equals, true)
+	}
+	return s.Distinct(equals)
 }
 
+// Sort is skipped on a pipeline whose source is known SORTED.
 func Sort[T constraints.Ordered](s Stream[T]) Stream[T] {
 	helper.RequireCanButNonNil(s)
-	return s.Sort(func(t, u T) bool { return t < u })
+	less := func(t, u T) bool { return t < u }
+	if p, ok := s.(*FastPipline[T]); ok {
+		return p.sort(less, true)
+	}
+	return s.Sort(less)
 }
 
 func Max[T constraints.Ordered](s Stream[T]) optional.Value[T] {
@@ -51,3 +82,26 @@ func Min[T constraints.Ordered](s Stream[T]) optional.Value[T] {
 	helper.RequireCanButNonNil(s)
 	return s.Min(func(t, u T) bool { return t < u })
 }
+
+// Fold accumulates s into a value of another type. Each chunk of a parallel
+// stream starts from identity and the partial results are merged in encounter
+// order with combiner, identity must therefore be neutral for combiner.
+func Fold[T, R any](s Stream[T], identity R, acc function.BiFunc[R, T, R], combiner function.BiFunc[R, R, R]) R {
+	helper.RequireCanButNonNil(s)
+	helper.RequireCanButNonNil(acc)
+	helper.RequireCanButNonNil(combiner)
+	var list []*R
+	forEachChunk(s, func() function.Consumer[T] {
+		val := identity
+		list = append(list, &val)
+		return func(t T) { val = acc.Apply(val, t) }
+	})
+	if len(list) == 0 {
+		return identity
+	}
+	val := *list[0]
+	for _, item := range list[1:] {
+		val = combiner.Apply(val, *item)
+	}
+	return val
+}
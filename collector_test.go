@@ -1,6 +1,8 @@
 package stream_test
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
 	"github.com/go-park/stream"
@@ -136,6 +138,16 @@ func TestConstraintOp(t *testing.T) {
 		assert.Equal(t, 9, s.Get())
 	})
 
+	// equal elements keep the last max and the first min
+	t.Run("ties", func(t *testing.T) {
+		list := []P{{"a", 1}, {"b", 2}, {"c", 2}, {"d", 1}}
+		byAge := func(p1, p2 P) bool { return p1.Age < p2.Age }
+		for _, build := range engines(list) {
+			assert.Equal(t, "c", build().Max(byAge).Get().Name)
+			assert.Equal(t, "a", build().Min(byAge).Get().Name)
+		}
+	})
+
 	// min
 	t.Run("min", func(t *testing.T) {
 		list := []int{1, 4, 7, 2, 5, 8, 3, 6, 9}
@@ -143,3 +155,52 @@ func TestConstraintOp(t *testing.T) {
 		assert.Equal(t, 1, s.Get())
 	})
 }
+
+func TestFold(t *testing.T) {
+	list := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	acc := func(r string, i int) string { return fmt.Sprintf("%s%d", r, i) }
+	concat := func(r1, r2 string) string { return r1 + r2 }
+	for name, build := range engines(list) {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, "123456789", stream.Fold(build(), "", acc, concat))
+			assert.Equal(t, 45, stream.Fold(build(), 0, func(r, i int) int { return r + i }, func(r1, r2 int) int { return r1 + r2 }))
+			// reduce is seeded by the first element and passes the next element
+			// before the accumulated value on every engine
+			strs := stream.ToList(stream.From(list...), func(i int) string { return fmt.Sprint(i) })
+			val := engines(strs)[name]().Reduce(func(next, acc string) string { return acc + next })
+			assert.Equal(t, "123456789", val.Get())
+			val = engines(strs)[name]().Reduce(concat)
+			assert.Equal(t, "987654321", val.Get())
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, "-", stream.Fold(stream.From[int](), "-", acc, concat))
+	})
+}
+
+func engines[T any](list []T) map[string]func() stream.Stream[T] {
+	return map[string]func() stream.Stream[T]{
+		"fast":            func() stream.Stream[T] { return stream.From(list...) },
+		"fast-parallel":   func() stream.Stream[T] { return stream.From(list...).Parallel() },
+		"simple":          func() stream.Stream[T] { return stream.Builder[T]().Source(list...).Simple() },
+		"simple-parallel": func() stream.Stream[T] { return stream.Builder[T]().Source(list...).Simple().Parallel() },
+	}
+}
+
+func TestToChan(t *testing.T) {
+	var list []int
+	for v := range stream.ToChan(stream.Range(1, 5), 0) {
+		list = append(list, v)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, list)
+
+	// an abandoned consumer closes the stream to release the producer
+	s := stream.Range(1, math.MaxInt)
+	ch := stream.ToChan(s, 1)
+	assert.Equal(t, 1, <-ch)
+	assert.Equal(t, 2, <-ch)
+	s.Close()
+	for range ch {
+	}
+}
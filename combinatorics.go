@@ -0,0 +1,21 @@
+package stream
+
+import "github.com/go-park/stream/support/collections"
+
+// Permutations streams the orderings of list lazily, combine it with Limit or
+// Filter rather than collecting n! elements.
+func Permutations[T any](list []T) Stream[[]T] {
+	return Builder[[]T]().iterator(collections.Permutations(list)).Build()
+}
+
+func Combinations[T any](list []T, k int) Stream[[]T] {
+	return Builder[[]T]().iterator(collections.Combinations(list, k)).Build()
+}
+
+func PowerSet[T any](list []T) Stream[[]T] {
+	return Builder[[]T]().iterator(collections.PowerSet(list)).Build()
+}
+
+func CartesianProduct[T any](lists ...[]T) Stream[[]T] {
+	return Builder[[]T]().iterator(collections.CartesianProduct(lists...)).Build()
+}
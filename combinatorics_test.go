@@ -0,0 +1,46 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombinatorics(t *testing.T) {
+	t.Run("permutations", func(t *testing.T) {
+		assert.Equal(t, [][]int{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1}},
+			stream.Permutations([]int{1, 2, 3}).ToSlice())
+		assert.Equal(t, [][]int{{}}, stream.Permutations([]int{}).ToSlice())
+	})
+
+	t.Run("permutations-lazy", func(t *testing.T) {
+		list := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+		s := stream.Permutations(list).
+			Filter(func(p []int) bool { return p[len(p)-1] == 19 }).
+			Limit(2)
+		assert.Equal(t, 2, len(s.ToSlice()))
+	})
+
+	t.Run("combinations", func(t *testing.T) {
+		assert.Equal(t, [][]string{{"a", "b"}, {"a", "c"}, {"b", "c"}},
+			stream.Combinations([]string{"a", "b", "c"}, 2).ToSlice())
+		assert.Equal(t, [][]string{{}}, stream.Combinations([]string{"a"}, 0).ToSlice())
+		assert.Equal(t, 0, len(stream.Combinations([]string{"a"}, 2).ToSlice()))
+		assert.Equal(t, 0, len(stream.Combinations([]string{"a"}, -1).ToSlice()))
+		assert.Equal(t, 184756, stream.Combinations(make([]int, 20), 10).Count())
+	})
+
+	t.Run("powerSet", func(t *testing.T) {
+		assert.Equal(t, [][]int{{}, {1}, {2}, {3}, {1, 2}, {1, 3}, {2, 3}, {1, 2, 3}},
+			stream.PowerSet([]int{1, 2, 3}).ToSlice())
+		assert.Equal(t, [][]int{{}}, stream.PowerSet([]int{}).ToSlice())
+	})
+
+	t.Run("cartesianProduct", func(t *testing.T) {
+		assert.Equal(t, [][]int{{1, 3}, {1, 4}, {2, 3}, {2, 4}},
+			stream.CartesianProduct([]int{1, 2}, []int{3, 4}).ToSlice())
+		assert.Equal(t, 0, len(stream.CartesianProduct([]int{1, 2}, []int{}).ToSlice()))
+		assert.Equal(t, [][]int{{}}, stream.CartesianProduct[int]().ToSlice())
+	})
+}
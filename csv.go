@@ -0,0 +1,380 @@
+package stream
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/function"
+)
+
+// CSVOptions configures FromCSV. The zero value reads comma separated
+// records under a header row and fails the stream on the first bad row.
+type CSVOptions struct {
+	// Comma separates the fields, ',' when zero.
+	Comma rune
+	// Comment starts a line that is skipped, none when zero.
+	Comment rune
+	// TimeLayout parses time.Time fields, time.RFC3339 when empty.
+	TimeLayout string
+	// OnError receives the error of each bad row, which is then skipped
+	// instead of failing the stream.
+	OnError func(error)
+}
+
+// RowError reports a field FromCSV could not convert.
+type RowError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("stream: csv line %d, column %q: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textUnmarshalType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// structField is an exported field of a struct and the name it goes by.
+type structField struct {
+	name  string
+	index []int
+	typ   reflect.Type
+}
+
+// structFields lists the exported fields of the struct typ in declaration
+// order, named by their tag or else their name. A "-" tag skips the field,
+// as do the fields promoted through an embedded pointer, which may be nil.
+func structFields(typ reflect.Type, tag string) []structField {
+	var fields []structField
+	for _, f := range reflect.VisibleFields(typ) {
+		if !f.IsExported() || f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get(tag) == "" ||
+			viaPointer(typ, f.Index) {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, structField{name: name, index: f.Index, typ: f.Type})
+	}
+	return fields
+}
+
+func viaPointer(typ reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		typ = typ.Field(i).Type
+		if typ.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldNamed finds the field going by name, or else by name in any case.
+func fieldNamed(fields []structField, name string) *structField {
+	var folded *structField
+	for i := range fields {
+		if fields[i].name == name {
+			return &fields[i]
+		}
+		if folded == nil && strings.EqualFold(fields[i].name, name) {
+			folded = &fields[i]
+		}
+	}
+	return folded
+}
+
+// csvFields lists the fields of the struct type T by their csv tag.
+func csvFields[T any]() ([]structField, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("stream: csv needs a struct, not %v", typ)
+	}
+	fields := structFields(typ, "csv")
+	for _, f := range fields {
+		if err := csvCheck(f.typ); err != nil {
+			return nil, fmt.Errorf("stream: csv field %s: %w", f.name, err)
+		}
+	}
+	return fields, nil
+}
+
+func csvCheck(typ reflect.Type) error {
+	if typ == timeType || reflect.PointerTo(typ).Implements(textUnmarshalType) {
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Pointer:
+		return csvCheck(typ.Elem())
+	}
+	return fmt.Errorf("unsupported type %v", typ)
+}
+
+// csvDecode sets v from s, an empty s leaves v at its zero value.
+func csvDecode(v reflect.Value, s, layout string) error {
+	if s == "" {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		return csvDecode(v.Elem(), s, layout)
+	}
+	if v.Type() == timeType {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			v.Set(reflect.ValueOf(t))
+		}
+		return err
+	}
+	if v.Addr().Type().Implements(textUnmarshalType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
+
+// csvEncode formats v the way csvDecode reads it back.
+func csvEncode(v reflect.Value, layout string) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(layout), nil
+	}
+	if v.Type().Implements(textMarshalType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			return time.Duration(v.Int()).String(), nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("unsupported type %v", v.Type())
+}
+
+// csvIterator decodes the records under the header row into structs.
+type csvIterator[T any] struct {
+	reader  *csv.Reader
+	fields  []structField
+	opts    CSVOptions
+	columns []*structField
+	next    T
+	ready   bool
+	done    bool
+	err     error
+}
+
+func (it *csvIterator[T]) HasNext() bool {
+	for !it.ready && !it.done {
+		if it.columns == nil && !it.header() {
+			break
+		}
+		record, err := it.reader.Read()
+		if err == nil {
+			err = it.decode(record)
+		}
+		var parse *csv.ParseError
+		switch {
+		case err == nil:
+			it.ready = true
+		case err == io.EOF:
+			it.done = true
+		case it.opts.OnError != nil && (errors.As(err, &parse) || errors.As(err, new(*RowError))):
+			it.opts.OnError(err)
+		default:
+			it.done, it.err = true, err
+		}
+	}
+	return it.ready
+}
+
+// header matches the columns of the first record to the fields, by name
+// and then case insensitively. Unmatched columns are ignored.
+func (it *csvIterator[T]) header() bool {
+	record, err := it.reader.Read()
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+		}
+		it.done = true
+		return false
+	}
+	it.columns = make([]*structField, len(record))
+	for i, name := range record {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		it.columns[i] = fieldNamed(it.fields, name)
+	}
+	return true
+}
+
+func (it *csvIterator[T]) decode(record []string) error {
+	var t T
+	v := reflect.ValueOf(&t).Elem()
+	for i, s := range record {
+		if i >= len(it.columns) || it.columns[i] == nil {
+			continue
+		}
+		if err := csvDecode(v.FieldByIndex(it.columns[i].index), s, it.opts.TimeLayout); err != nil {
+			line, _ := it.reader.FieldPos(i)
+			return &RowError{Line: line, Column: it.columns[i].name, Err: err}
+		}
+	}
+	it.next = t
+	return nil
+}
+
+func (it *csvIterator[T]) Next() T {
+	var t T
+	if it.HasNext() {
+		t, it.ready = it.next, false
+	}
+	return t
+}
+
+func (it *csvIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for it.HasNext() {
+		fn(it.Next())
+	}
+}
+
+func (it *csvIterator[T]) Err() error {
+	return it.err
+}
+
+// FromCSV decodes the records of r into structs, matching the header row
+// to the csv tags of their fields. Ints, uints, floats, bools, strings,
+// time.Time, time.Duration, pointers to them and encoding.TextUnmarshaler
+// are converted, an empty field leaves its zero value. A bad row fails
+// the stream, reported by Err, unless opts.OnError takes it.
+func FromCSV[T any](r io.Reader, opts CSVOptions) Stream[T] {
+	reader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	reader.Comment = opts.Comment
+	reader.ReuseRecord = true
+	if opts.TimeLayout == "" {
+		opts.TimeLayout = time.RFC3339
+	}
+	it := &csvIterator[T]{reader: reader, opts: opts}
+	it.fields, it.err = csvFields[T]()
+	it.done = it.err != nil
+	return Builder[T]().iterator(it).Build()
+}
+
+// ToCSV writes a header row and then a record per element of s to w, in
+// the format FromCSV reads with the same opts, of which Comma and
+// TimeLayout apply. A parallel s writes its records out of encounter
+// order. It returns the first error of writing or of s.
+func ToCSV[T any](s Stream[T], w io.Writer, opts CSVOptions) error {
+	helper.RequireCanButNonNil(s)
+	fields, err := csvFields[T]()
+	if err != nil {
+		return err
+	}
+	if opts.TimeLayout == "" {
+		opts.TimeLayout = time.RFC3339
+	}
+	writer := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		writer.Comma = opts.Comma
+	}
+	record := make([]string, len(fields))
+	for i, f := range fields {
+		record[i] = f.name
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	err = forEachErr(s, func(t T) error {
+		v := reflect.ValueOf(t)
+		for i, f := range fields {
+			var err error
+			if record[i], err = csvEncode(v.FieldByIndex(f.index), opts.TimeLayout); err != nil {
+				return err
+			}
+		}
+		return writer.Write(record)
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	return err
+}
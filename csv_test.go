@@ -0,0 +1,126 @@
+package stream_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+type trade struct {
+	Symbol string    `csv:"symbol"`
+	Qty    int       `csv:"qty"`
+	Price  float64   `csv:"price"`
+	Open   bool      `csv:"open"`
+	At     time.Time `csv:"at"`
+	Hold   time.Duration
+	Note   *string `csv:"note,omitempty"`
+	Secret string  `csv:"-"`
+}
+
+func TestFromCSV(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	t.Run("decode", func(t *testing.T) {
+		in := "\ufeffqty,SYMBOL,price,open,at,hold,extra,note\n" +
+			"10,ACME,1.5,true,2024-03-01T09:30:00Z,1m30s,x,\n" +
+			"-3,INIT,2,false,,,y,short\n"
+		s := stream.FromCSV[trade](strings.NewReader(in), stream.CSVOptions{})
+		list := s.ToSlice()
+		assert.NoError(t, s.Err())
+		note := "short"
+		assert.Equal(t, []trade{
+			{Symbol: "ACME", Qty: 10, Price: 1.5, Open: true, At: at, Hold: 90 * time.Second},
+			{Symbol: "INIT", Qty: -3, Price: 2, Note: &note},
+		}, list)
+	})
+	t.Run("options", func(t *testing.T) {
+		in := "symbol;at\n# skipped\nACME;01/03/2024\n"
+		s := stream.FromCSV[trade](strings.NewReader(in), stream.CSVOptions{
+			Comma:      ';',
+			Comment:    '#',
+			TimeLayout: "02/01/2006",
+		})
+		assert.Equal(t, []trade{{Symbol: "ACME", At: at.Truncate(24 * time.Hour)}}, s.ToSlice())
+	})
+	t.Run("bad row fails", func(t *testing.T) {
+		in := "symbol,qty\nA,1\nB,many\nC,3\n"
+		s := stream.FromCSV[trade](strings.NewReader(in), stream.CSVOptions{}).
+			MapToString(func(t trade) string { return t.Symbol })
+		assert.Equal(t, []string{"A"}, s.ToSlice())
+		var rowErr *stream.RowError
+		assert.True(t, errors.As(s.Err(), &rowErr))
+		assert.Equal(t, 3, rowErr.Line)
+		assert.Equal(t, "qty", rowErr.Column)
+		assert.ErrorIs(t, s.Err(), strconv.ErrSyntax)
+	})
+	t.Run("bad rows skipped", func(t *testing.T) {
+		in := "symbol,qty\nA,1\nB,many\nC\nD,4\n"
+		var errs []error
+		s := stream.FromCSV[trade](strings.NewReader(in), stream.CSVOptions{
+			OnError: func(err error) { errs = append(errs, err) },
+		})
+		assert.Equal(t, []int{1, 4}, stream.ToList(s, func(t trade) int { return t.Qty }))
+		assert.NoError(t, s.Err())
+		assert.Len(t, errs, 2)
+		assert.ErrorIs(t, errs[1], csv.ErrFieldCount)
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		s := stream.FromCSV[int](strings.NewReader("a\n1\n"), stream.CSVOptions{})
+		assert.Empty(t, s.ToSlice())
+		assert.Error(t, s.Err())
+	})
+	t.Run("empty", func(t *testing.T) {
+		s := stream.FromCSV[trade](strings.NewReader(""), stream.CSVOptions{})
+		assert.Empty(t, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+}
+
+func TestToCSV(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	note := "a, \"quoted\" note"
+	trades := []trade{
+		{Symbol: "ACME", Qty: 10, Price: 1.5, Open: true, At: at, Hold: time.Minute, Note: &note, Secret: "s"},
+		{Symbol: "INIT", Qty: -3, Price: 2, At: at},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, stream.ToCSV(stream.From(trades...), &buf, stream.CSVOptions{}))
+	assert.Equal(t, "symbol,qty,price,open,at,Hold,note\n"+
+		"ACME,10,1.5,true,2024-03-01T09:30:00Z,1m0s,\"a, \"\"quoted\"\" note\"\n"+
+		"INIT,-3,2,false,2024-03-01T09:30:00Z,0s,\n", buf.String())
+
+	back := stream.FromCSV[trade](&buf, stream.CSVOptions{}).ToSlice()
+	trades[0].Secret = ""
+	assert.Equal(t, trades, back)
+
+	assert.Error(t, stream.ToCSV(stream.From(1, 2), &buf, stream.CSVOptions{}))
+
+	t.Run("options", func(t *testing.T) {
+		opts := stream.CSVOptions{Comma: ';', TimeLayout: "02/01/2006 15:04"}
+		var buf bytes.Buffer
+		assert.NoError(t, stream.ToCSV(stream.From(trades[1]), &buf, opts))
+		assert.Equal(t, "symbol;qty;price;open;at;Hold;note\n"+
+			"INIT;-3;2;false;01/03/2024 09:30;0s;\n", buf.String())
+		assert.Equal(t, []trade{trades[1]}, stream.FromCSV[trade](&buf, opts).ToSlice())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, stream.ToCSV(stream.From[trade](), &buf, stream.CSVOptions{}))
+		assert.Equal(t, "symbol,qty,price,open,at,Hold,note\n", buf.String())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := stream.FromCSV[trade](&failingReader{data: "symbol\nA\n", err: errTransient}, stream.CSVOptions{})
+		assert.ErrorIs(t, stream.ToCSV(s, &buf, stream.CSVOptions{}), errTransient)
+		assert.Equal(t, "symbol,qty,price,open,at,Hold,note\n"+
+			"A,0,0,false,0001-01-01T00:00:00Z,0s,\n", buf.String())
+	})
+}
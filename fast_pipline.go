@@ -2,6 +2,8 @@ package stream
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"sort"
 	"sync"
 
@@ -13,18 +15,130 @@ import (
 )
 
 type FastPipline[T any] struct {
-	source    collections.Iterator[T]
-	opWrapper func(down function.Consumer[T]) function.Consumer[T]
-	cancel    context.CancelFunc
-	parallel  bool
+	source   collections.Iterator[T]
+	stages   []stage[T]
+	ctx      context.Context
+	cancel   context.CancelFunc
+	parallel bool
+	// buffer bounds the read ahead of a parallel run over a source of unknown size
+	buffer int
+	// from is the pipeline mapped into this one by mapName
+	from interface {
+		Explain() Plan
+		Err() error
+		Close()
+	}
+	mapName  string
+	observer Observer
+	// halted ends a sequential run early, once the pipeline mapped from this
+	// one needs no more elements
+	halted bool
+}
+
+type opKind int
+
+const (
+	opFilter opKind = iota
+	opMap
+	opLimit
+	opSkip
+	opDistinct
+	opSort
+	opReverse
+	opTopK
+)
+
+// stage is an intermediate operation. Streaming stages only have wrap,
+// Sort and Reverse only have barrier and see the whole upstream output at once.
+// Limit, Skip and Distinct have both, they stream when sequential and turn
+// into barriers when parallel, so their result does not depend on chunking.
+type stage[T any] struct {
+	op    opKind
+	name  string
+	label string
+	// elem is set on Filter and Map, so that adjacent ones can be fused
+	elem    func(t T) (T, bool)
+	wrap    func(down function.Consumer[T], stop func()) function.Consumer[T]
+	barrier func(run func(fac func() function.Consumer[T])) []T
+	// n and less are the arguments of Limit, Skip and Sort the optimiser rewrites
+	n    uint
+	less function.BiPredicate[T, T]
+	// natural is set on Sort and Distinct using the natural order of T,
+	// which the characteristics of the source can make redundant
+	natural bool
+}
+
+func elemStage[T any](op opKind, name string, elem func(t T) (T, bool)) stage[T] {
+	return stage[T]{
+		op:   op,
+		name: name,
+		elem: elem,
+		wrap: func(down function.Consumer[T], _ func()) function.Consumer[T] {
+			return func(t T) {
+				if v, ok := elem(t); ok {
+					down.Accept(v)
+				}
+			}
+		},
+	}
+}
+
+// whole turns fn into a barrier over the whole upstream output.
+func whole[T any](fn func(list []T) []T) func(run func(fac func() function.Consumer[T])) []T {
+	return func(run func(fac func() function.Consumer[T])) []T {
+		return fn(collect(run))
+	}
 }
 
 func (p *FastPipline[T]) Close() {
 	p.cancel()
+	p.release()
+	if p.from != nil {
+		p.from.Close()
+	}
+}
+
+// release closes a source holding resources, such as an open file.
+func (p *FastPipline[T]) release() {
+	if c, ok := p.source.(io.Closer); ok {
+		c.Close()
+	}
 }
 
+// Err returns the error which ended the source early, it is nil when the
+// source was exhausted or the stream closed.
+func (p *FastPipline[T]) Err() error {
+	if p.from != nil {
+		if err := p.from.Err(); err != nil {
+			return err
+		}
+	}
+	if f, ok := p.source.(interface{ Err() error }); ok {
+		return f.Err()
+	}
+	return nil
+}
+
+func (p *FastPipline[T]) done() <-chan struct{} {
+	return p.ctx.Done()
+}
+
+func (p *FastPipline[T]) closed() bool {
+	select {
+	case <-p.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// Parallel splits the source into GetParallelism chunks, each pushed
+// through the stages by a goroutine of its own. The callbacks of Filter,
+// Map, ForEach and the like then run concurrently, ForEach out of encounter
+// order, and must be safe for it. Parallel used to leave this engine
+// sequential.
 func (p *FastPipline[T]) Parallel() Stream[T] {
-	// p.parallel = true
+	p.parallel = true
 	return p
 }
 
@@ -33,130 +147,276 @@ func (p *FastPipline[T]) Sequential() Stream[T] {
 	return p
 }
 
+// Count returns the size of a SIZED source without running the pipeline
+// when only Map, Sort or Reverse stages follow it, their callbacks are then
+// not called.
 func (p *FastPipline[T]) Count() int {
-	acc := func(_ T, i int) int {
-		return i + 1
-	}
-	var list []*optional.Value[int]
-	fac := func() function.Consumer[T] {
-		val, op := reduceOp(acc, 0)
-		list = append(list, val)
-		return p.opWrapper(op)
+	if size, ok := p.optimise().size(); ok {
+		// the stream is consumed all the same
+		p.source = collections.IterableSlice[T]()
+		return size
 	}
-	p.exec(fac)
-	val, sum := reduceOp(func(i1, i2 int) int {
-		return i1 + i2
-	}, 0)
-	for _, item := range list {
-		item.IfNotEmpty(sum)
-	}
-	return val.Get()
+	return Fold[T](p, 0, func(i int, _ T) int { return i + 1 }, function.Sum[int])
 }
 
 func (p *FastPipline[T]) ToSlice() []T {
-	slices := make([][]T, 0)
+	return collect(p.exec)
+}
 
-	fac := func() function.Consumer[T] {
-		slices = append(slices, make([]T, 0))
-		index := len(slices) - 1
-		return p.opWrapper(func(t T) { slices[index] = append(slices[index], t) })
-	}
-	p.exec(fac)
+func (p *FastPipline[T]) ForEach(fn function.Consumer[T]) {
+	helper.RequireCanButNonNil(fn)
+	p.exec(func() function.Consumer[T] { return fn })
+}
+
+// collect concatenates in order what exec pushes into the chunk consumers.
+func collect[T any](exec func(fac func() function.Consumer[T])) []T {
+	var chunks []*[]T
+	exec(func() function.Consumer[T] {
+		chunk := new([]T)
+		chunks = append(chunks, chunk)
+		return func(t T) { *chunk = append(*chunk, t) }
+	})
 	var r []T
-	for _, v := range slices {
-		r = append(r, v...)
+	for _, v := range chunks {
+		r = append(r, *v...)
 	}
 	return r
 }
 
-func (p *FastPipline[T]) ForEach(fn function.Consumer[T]) {
-	helper.RequireCanButNonNil(fn)
-	fac := func() function.Consumer[T] { return p.opWrapper(fn) }
-	p.exec(fac)
+// exec runs the pipeline into the consumers made by fac, one per chunk.
+// Barriers split the stages into segments, each segment's output becomes
+// the source of the next one.
+func (p *FastPipline[T]) exec(fac func() function.Consumer[T]) {
+	defer p.release()
+	pl := p.optimise()
+	if pl.truncate >= 0 {
+		pl.source.(collections.Truncater).Truncate(pl.truncate)
+	}
+	source, stages, first := pl.source, pl.stages, 0
+	if p.observer != nil && p.from != nil {
+		// stages are numbered after the pipeline mapped into this one
+		first = len(p.from.Explain().Stages) + 1
+	}
+	for i := 0; i < len(stages); i++ {
+		if stages[i].wrap != nil && (!p.parallel || stages[i].barrier == nil) {
+			continue
+		}
+		upstream, segment, at := source, stages[:i], first
+		run := func(fac func() function.Consumer[T]) {
+			p.run(upstream, segment, at, fac)
+		}
+		var list []T
+		if p.observer == nil {
+			list = stages[i].barrier(run)
+		} else {
+			ev := StageEvent{Index: first + i, Stage: stages[i].info(p.parallel), Run: nextRun()}
+			list = observeBarrier(p.observer, ev, stages[i], run)
+		}
+		source, stages, first, i = collections.IterableSlice(list...), stages[i+1:], first+i+1, -1
+	}
+	p.run(source, stages, first, fac)
 }
 
-func (p *FastPipline[T]) exec(fac func() function.Consumer[T]) {
+func chain[T any](stages []stage[T], down function.Consumer[T], stop func()) function.Consumer[T] {
+	for i := len(stages) - 1; i >= 0; i-- {
+		down = stages[i].wrap(down, stop)
+	}
+	return down
+}
+
+// run pushes source through streaming stages, first is the plan index of
+// the first one. Parallel pipelines split the source into chunks, without
+// copying it when the source is a Spliterator, and in batches of at most
+// buffer elements when its size is unknown.
+func (p *FastPipline[T]) run(source collections.Iterator[T], stages []stage[T], first int, fac func() function.Consumer[T]) {
 	if !p.parallel {
-		p.source.ForEachRemaining(fac())
+		p.drain(source, stages, first, 0, false, fac())
 		return
 	}
-	var chunks [][]T
+	if _, ok := source.(collections.Spliterator[T]); ok || p.buffer <= 0 {
+		p.runChunks(splitSource(source, GetParallelism()), stages, first, 0, fac)
+		return
+	}
+	for chunk := 0; !p.closed(); {
+		var batch []T
+		for len(batch) < p.buffer && source.HasNext() {
+			batch = append(batch, source.Next())
+		}
+		if len(batch) == 0 {
+			return
+		}
+		chunks := splitSource(collections.IterableSlice(batch...), GetParallelism())
+		p.runChunks(chunks, stages, first, chunk, fac)
+		chunk += len(chunks)
+	}
+}
+
+// runChunks drains each chunk on its own goroutine, base is the index of the first chunk.
+func (p *FastPipline[T]) runChunks(chunks []collections.Iterator[T], stages []stage[T],
+	first, base int, fac func() function.Consumer[T]) {
 	var wg sync.WaitGroup
-	chunkNum := GetParallelism()
-	count := 0
-	p.source.ForEachRemaining(
-		func(v T) {
-			chunk := count % chunkNum
-			if len(chunks) == chunk {
-				chunks = append(chunks, make([]T, 0))
-			}
-			chunks[chunk] = append(chunks[chunk], v)
-			count++
-		})
-	for _, list := range chunks {
+	for i, chunk := range chunks {
 		wg.Add(1)
 		op := fac()
-		routine.RunArg(list, func(list []T) {
+		index := base + i
+		routine.RunArg(chunk, func(chunk collections.Iterator[T]) {
 			defer wg.Done()
-			for _, v := range list {
-				op(v)
-			}
+			p.drain(chunk, stages, first, index, true, op)
 		})
 	}
 	wg.Wait()
 }
 
-func (p *FastPipline[T]) Filter(pred function.Predicate[T]) Stream[T] {
-	helper.RequireCanButNonNil(pred)
-	wrap := p.opWrapper
-	p.opWrapper = func(down function.Consumer[T]) function.Consumer[T] {
-		op := func(t T) {
-			if pred.Test(t) {
-				down.Accept(t)
+func (p *FastPipline[T]) drain(source collections.Iterator[T], stages []stage[T],
+	first, chunk int, worker bool, down function.Consumer[T]) {
+	stopped := false
+	stop := func() { stopped = true }
+	var op function.Consumer[T]
+	if p.observer == nil {
+		op = chain(stages, down, stop)
+	} else {
+		events := make([]StageEvent, len(stages))
+		run := nextRun()
+		for i := range stages {
+			events[i] = StageEvent{Index: first + i, Stage: stages[i].info(p.parallel), Run: run, Chunk: chunk, Worker: worker}
+		}
+		// started last to first and ended first to last, so that the runs nest
+		for i := len(events) - 1; i >= 0; i-- {
+			p.observer.OnStart(events[i])
+		}
+		defer func() {
+			for _, ev := range events {
+				p.observer.OnEnd(ev, 0, 0)
 			}
+		}()
+		op = down
+		for i := len(stages) - 1; i >= 0; i-- {
+			op = observe(p.observer, events[i], stages[i], op, stop)
 		}
-		return wrap(op)
 	}
-	return p
+	if each, ok := source.(interface{ forEachWhile(func(T) bool) }); ok {
+		// pushed and ranged sources stop their loop once the stream is done
+		each.forEachWhile(func(t T) bool {
+			op(t)
+			return !stopped && !p.halted && !p.closed()
+		})
+		return
+	}
+	// a closed stream stops where it is, before its source is read again
+	for !stopped && !p.halted && !p.closed() && source.HasNext() {
+		op(source.Next())
+	}
 }
 
-func (p *FastPipline[T]) Limit(i uint) Stream[T] {
-	wrap := p.opWrapper
-	p.opWrapper = func(down function.Consumer[T]) function.Consumer[T] {
-		var num uint = 0
-		op := func(t T) {
-			if num < i {
-				down.Accept(t)
-				num++
+// splitSource cuts source into at most n chunks in encounter order by
+// splitting the largest part until n is reached or nothing can be split.
+func splitSource[T any](source collections.Iterator[T], n int) []collections.Iterator[T] {
+	sp, ok := source.(collections.Spliterator[T])
+	if !ok {
+		var list []T
+		source.ForEachRemaining(func(t T) { list = append(list, t) })
+		var chunks []collections.Iterator[T]
+		for _, chunk := range split(list, n) {
+			chunks = append(chunks, collections.IterableSlice(chunk...))
+		}
+		return chunks
+	}
+	parts := []collections.Spliterator[T]{sp}
+	for len(parts) < n {
+		largest := 0
+		for i, part := range parts {
+			if part.EstimateSize() > parts[largest].EstimateSize() {
+				largest = i
 			}
 		}
-		return wrap(op)
+		prefix := parts[largest].TrySplit()
+		if prefix == nil {
+			break
+		}
+		parts = append(parts[:largest], append([]collections.Spliterator[T]{prefix}, parts[largest:]...)...)
+	}
+	chunks := make([]collections.Iterator[T], 0, len(parts))
+	for _, part := range parts {
+		chunks = append(chunks, part)
 	}
+	return chunks
+}
+
+func (p *FastPipline[T]) forEachChunk(fac func() function.Consumer[T]) {
+	p.exec(fac)
+}
+
+func (p *FastPipline[T]) add(s stage[T]) *FastPipline[T] {
+	p.stages = append(p.stages, s)
 	return p
 }
 
+func (p *FastPipline[T]) Filter(pred function.Predicate[T]) Stream[T] {
+	helper.RequireCanButNonNil(pred)
+	return p.add(elemStage(opFilter, "Filter", func(t T) (T, bool) {
+		return t, pred.Test(t)
+	}))
+}
+
+func (p *FastPipline[T]) Limit(i uint) Stream[T] {
+	return p.add(stage[T]{
+		op:   opLimit,
+		name: fmt.Sprintf("Limit(%d)", i),
+		n:    i,
+		wrap: func(down function.Consumer[T], stop func()) function.Consumer[T] {
+			var num uint = 0
+			return func(t T) {
+				if num < i {
+					down.Accept(t)
+					num++
+				}
+				if num >= i {
+					stop()
+				}
+			}
+		},
+		barrier: whole(func(list []T) []T {
+			if uint(len(list)) > i {
+				return list[:i]
+			}
+			return list
+		}),
+	})
+}
+
 func (p *FastPipline[T]) Skip(i uint) Stream[T] {
-	wrap := p.opWrapper
-	p.opWrapper = func(down function.Consumer[T]) function.Consumer[T] {
-		var num uint = 0
-		op := func(t T) {
-			if num < i {
-				num++
-			} else {
-				down.Accept(t)
+	return p.add(stage[T]{
+		op:   opSkip,
+		name: fmt.Sprintf("Skip(%d)", i),
+		n:    i,
+		wrap: func(down function.Consumer[T], _ func()) function.Consumer[T] {
+			var num uint = 0
+			return func(t T) {
+				if num < i {
+					num++
+				} else {
+					down.Accept(t)
+				}
 			}
-		}
-		return wrap(op)
-	}
-	return p
+		},
+		barrier: whole(func(list []T) []T {
+			if uint(len(list)) > i {
+				return list[i:]
+			}
+			return nil
+		}),
+	})
 }
 
 func (p *FastPipline[T]) Distinct(equals function.BiPredicate[T, T]) Stream[T] {
+	return p.distinct(equals, false)
+}
+
+func (p *FastPipline[T]) distinct(equals function.BiPredicate[T, T], natural bool) *FastPipline[T] {
 	helper.RequireCanButNonNil(equals)
-	wrap := p.opWrapper
-	p.opWrapper = func(down function.Consumer[T]) function.Consumer[T] {
+	wrap := func(down function.Consumer[T], _ func()) function.Consumer[T] {
 		var list []T
-		op := func(v T) {
+		return func(v T) {
 			exists := false
 			for _, item := range list {
 				if equals.Test(v, item) {
@@ -169,44 +429,51 @@ func (p *FastPipline[T]) Distinct(equals function.BiPredicate[T, T]) Stream[T] {
 				down.Accept(v)
 			}
 		}
-		return wrap(op)
 	}
-	return p
+	return p.add(stage[T]{
+		op:      opDistinct,
+		name:    "Distinct",
+		natural: natural,
+		wrap:    wrap,
+		barrier: whole(func(list []T) []T {
+			var res []T
+			collections.ForEach(list, wrap(func(t T) { res = append(res, t) }, nil))
+			return res
+		}),
+	})
 }
 
 func (p *FastPipline[T]) Sort(less function.BiPredicate[T, T]) Stream[T] {
+	return p.sort(less, false)
+}
+
+func (p *FastPipline[T]) sort(less function.BiPredicate[T, T], natural bool) *FastPipline[T] {
 	helper.RequireCanButNonNil(less)
-	wrap := p.opWrapper
-	p.opWrapper = func(down function.Consumer[T]) function.Consumer[T] {
-		var list []T
-		op := func(t T) {
-			list = append(list, t)
-		}
-		p.exec(func() function.Consumer[T] { return wrap(op) })
-		sort.Slice(list, func(i, j int) bool {
-			return less(list[i], list[j])
-		})
-		p.source = collections.IterableSlice(list...)
-		return defultOpWrapper(down)
-	}
-	return p
+	return p.add(stage[T]{
+		op:      opSort,
+		name:    "Sort",
+		less:    less,
+		natural: natural,
+		barrier: whole(func(list []T) []T {
+			sort.Slice(list, func(i, j int) bool {
+				return less(list[i], list[j])
+			})
+			return list
+		}),
+	})
 }
 
 func (p *FastPipline[T]) Reverse() Stream[T] {
-	wrap := p.opWrapper
-	p.opWrapper = func(down function.Consumer[T]) function.Consumer[T] {
-		var list []T
-		op := func(t T) {
-			list = append(list, t)
-		}
-		p.exec(func() function.Consumer[T] { return wrap(op) })
-		for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
-			list[i], list[j] = list[j], list[i]
-		}
-		p.source = collections.IterableSlice(list...)
-		return defultOpWrapper(down)
-	}
-	return p
+	return p.add(stage[T]{
+		op:   opReverse,
+		name: "Reverse",
+		barrier: whole(func(list []T) []T {
+			for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+				list[i], list[j] = list[j], list[i]
+			}
+			return list
+		}),
+	})
 }
 
 func (p *FastPipline[T]) Max(less function.BiPredicate[T, T]) optional.Value[T] {
@@ -231,166 +498,130 @@ func (p *FastPipline[T]) Min(less function.BiPredicate[T, T]) optional.Value[T]
 
 func (p *FastPipline[T]) Map(mapper function.Func[T, T]) Stream[T] {
 	helper.RequireCanButNonNil(mapper)
-	wrap := p.opWrapper
-	p.opWrapper = func(down function.Consumer[T]) function.Consumer[T] {
-		op := func(t T) {
-			down.Accept(mapper(t))
-		}
-		return wrap(op)
-	}
-	return p
+	return p.add(elemStage(opMap, "Map", func(t T) (T, bool) {
+		return mapper(t), true
+	}))
 }
 
-func reduceOp[T, R any](acc function.BiFunc[T, R, R], identify R) (*optional.Value[R], function.Consumer[T]) {
-	val := optional.EmptyVal[R]()
-	return &val, func(v T) {
-		val.IfNotEmptyOrElse(
-			func(t R) { val = optional.ValOf(acc.Apply(v, t)) },
-			func() { val = optional.ValOf(acc.Apply(v, identify)) })
-	}
+func (p *FastPipline[T]) Reduce(acc function.BiFunc[T, T, T]) optional.Value[T] {
+	helper.RequireCanButNonNil(acc)
+	return reduceChunks[T](p, acc)
 }
 
-func (p *FastPipline[T]) reduceOp(acc function.BiFunc[T, T, T], identify T) (*optional.Value[T], function.Consumer[T]) {
-	val := optional.EmptyVal[T]()
-	return &val, func(v T) {
-		val.IfNotEmptyOrElse(
-			func(t T) {
-				val = optional.ValOf(acc.Apply(v, t))
-			},
-			func() {
-				val = optional.ValOf(v)
-			})
+// mapIterator is the source of a pipeline made by mapTo. Draining it runs
+// the pipeline it maps with the mapping as its last streaming stage, so a
+// sequential run stops the mapped pipeline as soon as the one built on it
+// needs no more elements, while a parallel one maps its chunks concurrently.
+type mapIterator[T, R any] struct {
+	from   *FastPipline[T]
+	mapper function.Func[T, R]
+	rest   collections.Iterator[R]
+}
+
+func (it *mapIterator[T, R]) buffered() collections.Iterator[R] {
+	if it.rest == nil {
+		var list []R
+		it.forEachWhile(func(r R) bool {
+			list = append(list, r)
+			return true
+		})
+		it.rest = collections.IterableSlice(list...)
 	}
+	return it.rest
 }
 
-func (p *FastPipline[T]) Reduce(acc function.BiFunc[T, T, T]) optional.Value[T] {
-	var list []*optional.Value[T]
-	var identify T
+func (it *mapIterator[T, R]) HasNext() bool {
+	return it.buffered().HasNext()
+}
 
-	fac := func() function.Consumer[T] {
-		val, op := p.reduceOp(acc, identify)
-		list = append(list, val)
-		return p.opWrapper(op)
-	}
-	p.exec(fac)
-	v, op := p.reduceOp(acc, identify)
-	for _, item := range list {
-		item.IfNotEmpty(op)
-	}
-	return *v
+func (it *mapIterator[T, R]) Next() R {
+	return it.buffered().Next()
 }
 
-func (p *FastPipline[T]) MapToAny(mapper function.Func[T, any]) Stream[any] {
-	helper.RequireCanButNonNil(mapper)
-	anyPipe := &FastPipline[any]{
-		cancel:   p.cancel,
-		parallel: p.parallel,
+func (it *mapIterator[T, R]) ForEachRemaining(fn function.Consumer[R]) {
+	it.forEachWhile(func(r R) bool {
+		fn(r)
+		return true
+	})
+}
+
+func (it *mapIterator[T, R]) forEachWhile(fn func(R) bool) {
+	if it.rest != nil {
+		for it.rest.HasNext() && fn(it.rest.Next()) {
+		}
+		return
+	}
+	it.rest = collections.IterableSlice[R]()
+	p := it.from
+	if !p.parallel {
+		p.exec(func() function.Consumer[T] {
+			return func(t T) {
+				if !p.halted && !fn(it.mapper(t)) {
+					p.halted = true
+				}
+			}
+		})
+		return
 	}
-	anyPipe.opWrapper = func(down function.Consumer[any]) function.Consumer[any] {
-		var list []any
-		op := func(t T) {
-			list = append(list, mapper(t))
+	list := collect(func(fac func() function.Consumer[R]) {
+		p.exec(func() function.Consumer[T] {
+			op := fac()
+			return func(t T) { op(it.mapper(t)) }
+		})
+	})
+	for _, r := range list {
+		if !fn(r) {
+			return
 		}
-		p.exec(func() function.Consumer[T] { return p.opWrapper(op) })
-		anyPipe.source = collections.IterableSlice(list...)
-		return defultOpWrapper(down)
 	}
-	return anyPipe
 }
 
-func (p *FastPipline[T]) MapToString(mapper function.Func[T, string]) Stream[string] {
+// mapTo starts a pipeline of another element type on top of p.
+func mapTo[T, R any](p *FastPipline[T], name string, mapper function.Func[T, R]) *FastPipline[R] {
 	helper.RequireCanButNonNil(mapper)
-	strPipe := &FastPipline[string]{
+	return &FastPipline[R]{
+		source:   &mapIterator[T, R]{from: p, mapper: mapper},
+		ctx:      p.ctx,
 		cancel:   p.cancel,
 		parallel: p.parallel,
+		from:     p,
+		mapName:  name,
+		observer: p.observer,
 	}
-	var list []string
-	op := func(t T) {
-		list = append(list, mapper(t))
-	}
-	strPipe.opWrapper = func(down function.Consumer[string]) function.Consumer[string] {
-		p.exec(func() function.Consumer[T] { return p.opWrapper(op) })
-		strPipe.source = collections.IterableSlice(list...)
-		return defultOpWrapper(down)
-	}
-	return strPipe
+}
+
+func (p *FastPipline[T]) MapToAny(mapper function.Func[T, any]) Stream[any] {
+	return mapTo(p, "MapToAny", mapper)
+}
+
+func (p *FastPipline[T]) MapToString(mapper function.Func[T, string]) Stream[string] {
+	return mapTo(p, "MapToString", mapper)
 }
 
 func (p *FastPipline[T]) MapToInt(mapper function.Func[T, int]) Stream[int] {
-	intPipe := &FastPipline[int]{
-		cancel:   p.cancel,
-		parallel: p.parallel,
-	}
-	intPipe.opWrapper = func(down function.Consumer[int]) function.Consumer[int] {
-		var list []int
-		op := func(t T) {
-			list = append(list, mapper(t))
-		}
-		p.exec(func() function.Consumer[T] { return p.opWrapper(op) })
-		intPipe.source = collections.IterableSlice(list...)
-		return defultOpWrapper(down)
-	}
-	return intPipe
+	return mapTo(p, "MapToInt", mapper)
 }
 
 func (p *FastPipline[T]) MapToFloat(mapper function.Func[T, float64]) Stream[float64] {
-	floatPipe := &FastPipline[float64]{
-		cancel:   p.cancel,
-		parallel: p.parallel,
-	}
-	floatPipe.opWrapper = func(down function.Consumer[float64]) function.Consumer[float64] {
-		var list []float64
-		op := func(t T) {
-			list = append(list, mapper(t))
-		}
-		p.exec(func() function.Consumer[T] { return p.opWrapper(op) })
-		floatPipe.source = collections.IterableSlice(list...)
-		return defultOpWrapper(down)
-	}
-	return floatPipe
+	return mapTo(p, "MapToFloat", mapper)
 }
 
 func (p *FastPipline[T]) AnyMatch(pred function.Predicate[T]) bool {
 	helper.RequireCanButNonNil(pred)
-	match := false
-	p.ForEach(func(t T) {
-		if pred.Test(t) {
-			match = true
-			p.cancel()
-		}
-	})
-	return match
+	return !p.Filter(pred).FindAny().IsEmpty()
 }
 
 func (p *FastPipline[T]) AllMatch(pred function.Predicate[T]) bool {
 	helper.RequireCanButNonNil(pred)
-	match := true
-	p.ForEach(func(t T) {
-		if !pred.Test(t) {
-			match = false
-			p.cancel()
-		}
-	})
-	return match
+	return !p.AnyMatch(pred.Negate())
 }
 
 func (p *FastPipline[T]) NoneMatch(pred function.Predicate[T]) bool {
 	helper.RequireCanButNonNil(pred)
-	match := false
-	p.ForEach(func(t T) {
-		if pred.Test(t) {
-			match = true
-			p.cancel()
-		}
-	})
-	return !match
+	return !p.AnyMatch(pred)
 }
 
+// FindAny returns the first element, a sequential pipeline stops pulling its source right after.
 func (p *FastPipline[T]) FindAny() optional.Value[T] {
-	val := optional.EmptyVal[T]()
-	p.ForEach(func(t T) {
-		val = optional.ValOf(t)
-		p.cancel()
-	})
-	return val
+	return p.Limit(1).Reduce(func(_, t T) T { return t })
 }
@@ -0,0 +1,147 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/function"
+)
+
+// WriteTo writes each element of s to w as format renders it, on a line
+// of its own. A parallel s writes its lines out of encounter order. It
+// returns the first error of writing or of s.
+func WriteTo[T any](s Stream[T], w io.Writer, format func(T) string) error {
+	helper.RequireCanButNonNil(format)
+	return writeEach(s, w, func(bw *bufio.Writer, t T) error {
+		bw.WriteString(format(t))
+		return bw.WriteByte('\n')
+	})
+}
+
+// Join renders the elements of s as fmt.Sprint does, separated by sep, in
+// encounter order.
+func Join[T any](s Stream[T], sep string) string {
+	helper.RequireCanButNonNil(s)
+	var sb strings.Builder
+	for i, t := range s.ToSlice() {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		fmt.Fprint(&sb, t)
+	}
+	return sb.String()
+}
+
+// renderErr holds the error a template failed with, for Err to report it.
+type renderErr struct {
+	err error
+}
+
+func (e *renderErr) Close() {}
+
+func (e *renderErr) Err() error {
+	return e.err
+}
+
+// Format renders each element of s with the text/template tmpl, as in
+// Format(s, "{{.Name}} is {{.Age}}"). A malformed tmpl is returned as an
+// error, one failing to render an element ends the stream and is
+// reported by Err.
+func Format[T any](s Stream[T], tmpl string) (Stream[string], error) {
+	helper.RequireCanButNonNil(s)
+	t, err := template.New("format").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	failed := &renderErr{}
+	return fromEach(func(down function.Consumer[string], more func() bool) {
+		var buf bytes.Buffer
+		forEachUpstream(s, func() bool { return failed.err == nil && more() }, func(v T) {
+			buf.Reset()
+			if failed.err = t.Execute(&buf, v); failed.err == nil {
+				down(buf.String())
+			}
+		})
+	}, s, failed), nil
+}
+
+// TableOptions configures ToTable.
+type TableOptions struct {
+	// Columns selects the fields shown and their order, by their table tag
+	// or else their name, in any case. All fields are shown when empty.
+	Columns []string
+	// NoHeader leaves out the row of column names.
+	NoHeader bool
+	// Padding is the space between columns, 2 when zero.
+	Padding int
+}
+
+// ToTable writes the struct elements of s to w as a table aligned by
+// text/tabwriter, a row per element under a row of column names. The
+// fields are rendered as fmt.Sprint does, a nil pointer as nothing. A
+// parallel s writes its rows out of encounter order. It returns the first
+// error of writing or of s.
+func ToTable[T any](s Stream[T], w io.Writer, opts TableOptions) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("stream: table needs a struct, not %v", typ)
+	}
+	fields := structFields(typ, "table")
+	if len(opts.Columns) > 0 {
+		selected := make([]structField, len(opts.Columns))
+		for i, name := range opts.Columns {
+			f := fieldNamed(fields, name)
+			if f == nil {
+				return fmt.Errorf("stream: table has no column %q", name)
+			}
+			selected[i] = *f
+		}
+		fields = selected
+	}
+	if opts.Padding == 0 {
+		opts.Padding = 2
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, opts.Padding, ' ', 0)
+	row := make([]string, len(fields))
+	writeRow := func() error {
+		_, err := io.WriteString(tw, strings.Join(row, "\t")+"\n")
+		return err
+	}
+	if !opts.NoHeader {
+		for i, f := range fields {
+			row[i] = f.name
+		}
+		if err := writeRow(); err != nil {
+			return err
+		}
+	}
+	err := forEachErr(s, func(t T) error {
+		v := reflect.ValueOf(t)
+		for i, f := range fields {
+			row[i] = cell(v.FieldByIndex(f.index))
+		}
+		return writeRow()
+	})
+	if flushErr := tw.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// cell renders v on a single line without the tabs separating the cells.
+func cell(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	return strings.NewReplacer("\t", " ", "\n", " ").Replace(fmt.Sprint(v.Interface()))
+}
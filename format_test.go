@@ -0,0 +1,108 @@
+package stream_test
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+type host struct {
+	Name   string `table:"NAME"`
+	Port   int    `table:"PORT"`
+	Up     bool   `table:"UP"`
+	Note   *string
+	secret string
+}
+
+func hosts() []host {
+	note := "primary\tdb"
+	return []host{
+		{Name: "alpha", Port: 80, Up: true, Note: &note},
+		{Name: "beta-long-name", Port: 8080},
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	err := stream.WriteTo(stream.Range(1, 3), &buf, func(i int) string { return strconv.Itoa(i * i) })
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n4\n9\n", buf.String())
+
+	buf.Reset()
+	s := stream.FromLines(&failingReader{data: "a\n", err: errTransient})
+	assert.ErrorIs(t, stream.WriteTo(s, &buf, strings.ToUpper), errTransient)
+	assert.Equal(t, "A\n", buf.String())
+}
+
+func TestJoinStrings(t *testing.T) {
+	assert.Equal(t, "1, 2, 3", stream.Join(stream.Range(1, 3), ", "))
+	assert.Equal(t, "", stream.Join(stream.From[string](), ", "))
+	assert.Equal(t, "a-b", stream.Join(stream.From("a", "b").Parallel(), "-"))
+}
+
+func TestFormat(t *testing.T) {
+	t.Run("template", func(t *testing.T) {
+		s, err := stream.Format(stream.From(hosts()...), "{{.Name}}:{{.Port}}{{if .Up}} up{{end}}")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"alpha:80 up", "beta-long-name:8080"}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+	t.Run("limit", func(t *testing.T) {
+		s, err := stream.Format(stream.Range(1, 1000), "#{{.}}")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"#1", "#2"}, s.Limit(2).ToSlice())
+	})
+	t.Run("malformed", func(t *testing.T) {
+		_, err := stream.Format(stream.Range(1, 3), "{{.")
+		assert.Error(t, err)
+	})
+	t.Run("failing", func(t *testing.T) {
+		s, err := stream.Format(stream.From(hosts()...), "{{.Missing}}")
+		assert.NoError(t, err)
+		assert.Empty(t, s.ToSlice())
+		assert.Error(t, s.Err())
+
+		// the stream ends at the element failing to render
+		s, err = stream.Format(stream.Range(1, math.MaxInt), "{{if eq . 3}}{{.Missing}}{{end}}{{.}}")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1", "2"}, s.ToSlice())
+		assert.Error(t, s.Err())
+	})
+	t.Run("upstream error", func(t *testing.T) {
+		s, err := stream.Format(stream.FromLines(&failingReader{data: "a\nb\n", err: errTransient}), "<{{.}}>")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"<a>", "<b>"}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errTransient)
+	})
+}
+
+func TestToTable(t *testing.T) {
+	t.Run("all columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, stream.ToTable(stream.From(hosts()...), &buf, stream.TableOptions{}))
+		assert.Equal(t, ""+
+			"NAME            PORT  UP     Note\n"+
+			"alpha           80    true   primary db\n"+
+			"beta-long-name  8080  false  \n", buf.String())
+	})
+	t.Run("columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := stream.ToTable(stream.From(hosts()...), &buf, stream.TableOptions{
+			Columns:  []string{"port", "NAME"},
+			NoHeader: true,
+			Padding:  1,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "80   alpha\n8080 beta-long-name\n", buf.String())
+	})
+	t.Run("errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Error(t, stream.ToTable(stream.From(hosts()...), &buf, stream.TableOptions{Columns: []string{"secret"}}))
+		assert.Error(t, stream.ToTable(stream.Range(1, 3), &buf, stream.TableOptions{}))
+	})
+}
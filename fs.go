@@ -0,0 +1,321 @@
+package stream
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+)
+
+// FileEntry is a file or directory found by FromFS or FromGlob.
+type FileEntry struct {
+	// Path names the file within the walked file system, or as matched by
+	// FromGlob.
+	Path string
+	// Depth counts the directories between the root and the file, the
+	// root itself is at depth 0. FromGlob leaves it at 0.
+	Depth int
+	fs.FileInfo
+}
+
+// SymlinkPolicy tells FromFS what to do with symbolic links.
+type SymlinkPolicy int
+
+const (
+	// SymlinkKeep yields links as they are, without following them.
+	SymlinkKeep SymlinkPolicy = iota
+	// SymlinkSkip leaves links out.
+	SymlinkSkip
+	// SymlinkFollow yields links as their target and walks the directories
+	// they point to. A link back to one of its own directories is not
+	// followed, which is only detected on file systems whose FileInfo
+	// os.SameFile understands, such as os.DirFS.
+	SymlinkFollow
+)
+
+// FSOptions configures FromFS. The zero value yields every file and
+// directory under the root and fails the stream on the first error.
+type FSOptions struct {
+	// Pattern keeps the entries whose name matches it, as path.Match does.
+	// Directories are walked whether they match or not.
+	Pattern string
+	// MaxDepth stops the walk that many directories below the root,
+	// 0 walks all the way down.
+	MaxDepth int
+	// Symlinks is the policy for symbolic links.
+	Symlinks SymlinkPolicy
+	// FilesOnly leaves directories out, they are still walked.
+	FilesOnly bool
+	// SkipDirs names the directories which are neither yielded nor walked,
+	// as path.Match patterns such as ".git" or "node_modules".
+	SkipDirs []string
+	// OnError receives the errors of the walk, which goes on past the
+	// failing entry instead of failing the stream.
+	OnError func(error)
+}
+
+// errStop ends a walk the stream needs no more of.
+var errStop = errors.New("stream: stop walking")
+
+// fsIterator walks fsys when drained, so that a short-circuiting stage
+// ends the walk. HasNext and Next walk it whole on first use.
+type fsIterator struct {
+	fsys fs.FS
+	root string
+	opts FSOptions
+	err  error
+	rest collections.Iterator[FileEntry]
+	// glob holds a pattern per level below root, only the entries matching
+	// them all are walked and the deepest ones yielded under prefix
+	glob   []string
+	prefix string
+}
+
+func (it *fsIterator) buffered() collections.Iterator[FileEntry] {
+	if it.rest == nil {
+		var list []FileEntry
+		it.forEachWhile(func(e FileEntry) bool {
+			list = append(list, e)
+			return true
+		})
+		it.rest = collections.IterableSlice(list...)
+	}
+	return it.rest
+}
+
+func (it *fsIterator) HasNext() bool {
+	return it.buffered().HasNext()
+}
+
+func (it *fsIterator) Next() FileEntry {
+	return it.buffered().Next()
+}
+
+func (it *fsIterator) ForEachRemaining(fn function.Consumer[FileEntry]) {
+	it.forEachWhile(func(e FileEntry) bool {
+		fn(e)
+		return true
+	})
+}
+
+func (it *fsIterator) forEachWhile(fn func(FileEntry) bool) {
+	if it.rest != nil {
+		for it.rest.HasNext() {
+			if !fn(it.rest.Next()) {
+				return
+			}
+		}
+		return
+	}
+	it.rest = collections.IterableSlice[FileEntry]()
+	if it.err != nil {
+		return
+	}
+	if err := it.walk(it.root, 0, false, fn); err != errStop {
+		it.err = err
+	}
+}
+
+func (it *fsIterator) Err() error {
+	return it.err
+}
+
+// walk yields the entries under root, which is at depth. A followed link
+// is walked from its target, whose entry was already yielded.
+func (it *fsIterator) walk(root string, depth int, linked bool, yield func(FileEntry) bool) error {
+	return fs.WalkDir(it.fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if it.glob != nil && errors.Is(err, fs.ErrNotExist) {
+				// removed since it was listed
+				return nil
+			}
+			return it.fail(err)
+		}
+		if linked && name == root {
+			return nil
+		}
+		e := FileEntry{Path: name, Depth: depth + relDepth(root, name)}
+		if it.glob != nil {
+			return it.walkGlob(name, d, e, yield)
+		}
+		follow := false
+		if d.Type()&fs.ModeSymlink != 0 {
+			switch it.opts.Symlinks {
+			case SymlinkSkip:
+				return nil
+			case SymlinkFollow:
+				if e.FileInfo, err = fs.Stat(it.fsys, name); err != nil {
+					return it.fail(err)
+				}
+				follow = e.IsDir() && !it.loops(name, e.FileInfo)
+			}
+		}
+		if e.FileInfo == nil {
+			if e.FileInfo, err = d.Info(); err != nil {
+				return it.fail(err)
+			}
+		}
+		if e.IsDir() && name != it.root && matchAny(it.opts.SkipDirs, e.Name()) {
+			return skipDir(d)
+		}
+		if !(e.IsDir() && it.opts.FilesOnly) && (it.opts.Pattern == "" || matchAny([]string{it.opts.Pattern}, e.Name())) {
+			if !yield(e) {
+				return errStop
+			}
+		}
+		if it.opts.MaxDepth > 0 && e.Depth >= it.opts.MaxDepth && e.IsDir() {
+			return skipDir(d)
+		}
+		if follow {
+			return it.walk(name, e.Depth, true, yield)
+		}
+		return nil
+	})
+}
+
+// walkGlob yields e if it matches the whole glob, and walks it while it
+// matches its first levels.
+func (it *fsIterator) walkGlob(name string, d fs.DirEntry, e FileEntry, yield func(FileEntry) bool) error {
+	if e.Depth == 0 {
+		return nil
+	}
+	if ok, _ := filepath.Match(it.glob[e.Depth-1], d.Name()); !ok {
+		return skipDir(d)
+	}
+	if e.Depth < len(it.glob) {
+		if d.Type()&fs.ModeSymlink != 0 {
+			// walked from the target, as filepath.Glob reads through links
+			if info, err := fs.Stat(it.fsys, name); err == nil && info.IsDir() {
+				return it.walk(name, e.Depth, true, yield)
+			}
+		}
+		return nil
+	}
+	info, err := fs.Stat(it.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return it.fail(err)
+	}
+	if !yield(FileEntry{Path: filepath.Join(it.prefix, filepath.FromSlash(name)), FileInfo: info}) {
+		return errStop
+	}
+	return skipDir(d)
+}
+
+// skipDir skips the directory d, a link to one has nothing to skip.
+func skipDir(d fs.DirEntry) error {
+	if d.IsDir() {
+		return fs.SkipDir
+	}
+	return nil
+}
+
+// loops tells whether the directory target of the link at name is one of
+// the directories name is in.
+func (it *fsIterator) loops(name string, target fs.FileInfo) bool {
+	for dir := path.Dir(name); ; dir = path.Dir(dir) {
+		if info, err := fs.Stat(it.fsys, dir); err == nil && os.SameFile(info, target) {
+			return true
+		}
+		if dir == "." || dir == "/" {
+			return false
+		}
+	}
+}
+
+func (it *fsIterator) fail(err error) error {
+	if it.opts.OnError == nil {
+		return err
+	}
+	it.opts.OnError(err)
+	return nil
+}
+
+func relDepth(root, name string) int {
+	if name == root {
+		return 0
+	}
+	if root != "." {
+		name = name[len(root)+1:]
+	}
+	return strings.Count(name, "/") + 1
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FromFS walks fsys from root in lexical order, as fs.WalkDir does, and
+// yields an entry per file and directory opts keeps. The walk stops with
+// the stream, its first error fails the stream and is reported by Err
+// unless opts.OnError takes it.
+func FromFS(fsys fs.FS, root string, opts FSOptions) Stream[FileEntry] {
+	it := &fsIterator{fsys: fsys, root: root, opts: opts}
+	for _, pattern := range append([]string{opts.Pattern}, opts.SkipDirs...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			it.err = err
+		}
+	}
+	return Builder[FileEntry]().iterator(it).Build()
+}
+
+// FromGlob yields the files of the operating system matching pattern, as
+// filepath.Glob does, in lexical order. The directories are read as the
+// stream goes, from the deepest one the pattern names without wildcards.
+// A malformed pattern or a file that cannot be read fails the stream and
+// is reported by Err.
+func FromGlob(pattern string) Stream[FileEntry] {
+	it := &fsIterator{root: "."}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		it.err = err
+		return Builder[FileEntry]().iterator(it).Build()
+	}
+	levels := strings.Split(pattern, string(filepath.Separator))
+	static := 0
+	for static < len(levels)-1 && !hasMeta(levels[static]) {
+		static++
+	}
+	it.prefix, it.glob = strings.Join(levels[:static], string(filepath.Separator)), levels[static:]
+	if it.prefix == "" && static > 0 {
+		it.prefix = string(filepath.Separator)
+	}
+	dir := it.prefix
+	if dir == "" {
+		dir = "."
+	}
+	it.fsys = os.DirFS(dir)
+	return Builder[FileEntry]().iterator(it).Build()
+}
+
+// hasMeta tells whether path holds any of the special characters of
+// filepath.Match.
+func hasMeta(path string) bool {
+	magic := `*?[`
+	if runtime.GOOS != "windows" {
+		magic = `*?[\`
+	}
+	return strings.ContainsAny(path, magic)
+}
+
+// errIterator reports err once its elements are exhausted.
+type errIterator[T any] struct {
+	collections.Iterator[T]
+	err error
+}
+
+func (it *errIterator[T]) Err() error {
+	return it.err
+}
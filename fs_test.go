@@ -0,0 +1,179 @@
+package stream_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func paths(s stream.Stream[stream.FileEntry]) []string {
+	return stream.ToList(s, func(e stream.FileEntry) string { return e.Path })
+}
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":                {Data: []byte("module x")},
+		"main.go":               {Data: []byte("package main")},
+		"cmd/tool/tool.go":      {Data: []byte("package tool")},
+		"cmd/tool/README.md":    {Data: []byte("# tool")},
+		"internal/a/a.go":       {Data: []byte("package a")},
+		".git/HEAD":             {Data: []byte("ref")},
+		"vendor/dep/dep.go":     {Data: []byte("package dep")},
+		"internal/a/a_test.go":  {Data: []byte("package a")},
+		"internal/b/b.go":       {Data: []byte("package b")},
+		"internal/b/testdata/x": {Data: []byte("x")},
+	}
+	t.Run("all", func(t *testing.T) {
+		s := stream.FromFS(fsys, ".", stream.FSOptions{})
+		assert.Equal(t, []string{
+			".", ".git", ".git/HEAD", "cmd", "cmd/tool", "cmd/tool/README.md", "cmd/tool/tool.go",
+			"go.mod", "internal", "internal/a", "internal/a/a.go", "internal/a/a_test.go",
+			"internal/b", "internal/b/b.go", "internal/b/testdata", "internal/b/testdata/x",
+			"main.go", "vendor", "vendor/dep", "vendor/dep/dep.go",
+		}, paths(s))
+		assert.NoError(t, s.Err())
+	})
+	t.Run("find", func(t *testing.T) {
+		s := stream.FromFS(fsys, ".", stream.FSOptions{
+			Pattern:   "*.go",
+			FilesOnly: true,
+			SkipDirs:  []string{".git", "vendor", "testdata"},
+		}).Filter(func(e stream.FileEntry) bool { return e.Size() > 9 })
+		assert.Equal(t, []string{"cmd/tool/tool.go", "main.go"}, paths(s))
+	})
+	t.Run("max depth", func(t *testing.T) {
+		s := stream.FromFS(fsys, "internal", stream.FSOptions{MaxDepth: 1})
+		assert.Equal(t, []string{"internal", "internal/a", "internal/b"}, paths(s))
+		depths := stream.ToList(stream.FromFS(fsys, "internal/b", stream.FSOptions{}), func(e stream.FileEntry) int {
+			return e.Depth
+		})
+		assert.Equal(t, []int{0, 1, 1, 2}, depths)
+	})
+	t.Run("limit stops the walk", func(t *testing.T) {
+		opened := 0
+		counting := countingFS{MapFS: fsys, opened: &opened}
+		s := stream.FromFS(counting, ".", stream.FSOptions{FilesOnly: true}).Limit(1)
+		assert.Equal(t, []string{".git/HEAD"}, paths(s))
+		assert.Equal(t, 2, opened)
+	})
+	t.Run("errors", func(t *testing.T) {
+		s := stream.FromFS(fsys, "missing", stream.FSOptions{})
+		assert.Empty(t, paths(s))
+		assert.ErrorIs(t, s.Err(), fs.ErrNotExist)
+
+		var errs []error
+		s = stream.FromFS(fsys, "missing", stream.FSOptions{OnError: func(err error) { errs = append(errs, err) }})
+		assert.Empty(t, paths(s))
+		assert.NoError(t, s.Err())
+		assert.Len(t, errs, 1)
+
+		s = stream.FromFS(fsys, ".", stream.FSOptions{Pattern: "["})
+		assert.Empty(t, paths(s))
+		assert.ErrorIs(t, s.Err(), path.ErrBadPattern)
+	})
+	t.Run("parallel", func(t *testing.T) {
+		s := stream.FromFS(fsys, "cmd", stream.FSOptions{FilesOnly: true}).Parallel().
+			MapToString(func(e stream.FileEntry) string { return e.Path })
+		assert.Equal(t, []string{"cmd/tool/README.md", "cmd/tool/tool.go"}, s.ToSlice())
+	})
+}
+
+// countingFS counts the directories read.
+type countingFS struct {
+	fstest.MapFS
+	opened *int
+}
+
+func (c countingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	*c.opened++
+	return c.MapFS.ReadDir(name)
+}
+
+func TestFromFSSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a", "b", "f"), nil, 0o600))
+	if err := os.Symlink("..", filepath.Join(dir, "a", "b", "up")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	assert.NoError(t, os.Symlink("a", filepath.Join(dir, "link")))
+	fsys := os.DirFS(dir)
+	tests := []struct {
+		name   string
+		policy stream.SymlinkPolicy
+		want   []string
+	}{
+		{"keep", stream.SymlinkKeep, []string{".", "a", "a/b", "a/b/f", "a/b/up", "link"}},
+		{"skip", stream.SymlinkSkip, []string{".", "a", "a/b", "a/b/f"}},
+		// a/b/up and link/b/up loop back to a and are not followed
+		{"follow", stream.SymlinkFollow, []string{".", "a", "a/b", "a/b/f", "a/b/up", "link", "link/b", "link/b/f", "link/b/up"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stream.FromFS(fsys, ".", stream.FSOptions{Symlinks: tt.policy})
+			assert.Equal(t, tt.want, paths(s))
+			assert.NoError(t, s.Err())
+		})
+	}
+}
+
+func TestFromGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "a.txt", "c.log"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600))
+	}
+	s := stream.FromGlob(filepath.Join(dir, "*.txt"))
+	assert.Equal(t, []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, paths(s))
+	assert.NoError(t, s.Err())
+
+	s = stream.FromGlob("[")
+	assert.Empty(t, paths(s))
+	assert.True(t, errors.Is(s.Err(), filepath.ErrBadPattern))
+
+	t.Run("levels", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"x/1.txt", "x/2.log", "y/3.txt", "y/z/4.txt", "5.txt"} {
+			assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o700))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+		}
+		s := stream.FromGlob(filepath.Join(dir, "*", "*.txt"))
+		assert.Equal(t, []string{filepath.Join(dir, "x", "1.txt"), filepath.Join(dir, "y", "3.txt")}, paths(s))
+		assert.NoError(t, s.Err())
+		assert.Equal(t, []string{filepath.Join(dir, "y", "z")}, paths(stream.FromGlob(filepath.Join(dir, "y", "?"))))
+		assert.Equal(t, []string{filepath.Join(dir, "5.txt")}, paths(stream.FromGlob(filepath.Join(dir, "5.txt"))))
+		assert.Empty(t, paths(stream.FromGlob(filepath.Join(dir, "6.txt"))))
+		s = stream.FromGlob(filepath.Join(dir, "missing", "*"))
+		assert.Empty(t, paths(s))
+		assert.NoError(t, s.Err())
+		// links are read through, as filepath.Glob does
+		if err := os.Symlink(filepath.Join(dir, "x"), filepath.Join(dir, "l")); err == nil {
+			want, _ := filepath.Glob(filepath.Join(dir, "*", "*.txt"))
+			assert.Equal(t, want, paths(stream.FromGlob(filepath.Join(dir, "*", "*.txt"))))
+			assert.Contains(t, want, filepath.Join(dir, "l", "1.txt"))
+		}
+	})
+
+	t.Run("lazy", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "a"), 0o700))
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "b"), 0o700))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "a", "1.txt"), nil, 0o600))
+		var list []string
+		stream.FromGlob(filepath.Join(dir, "*", "*.txt")).ForEach(func(e stream.FileEntry) {
+			list = append(list, e.Name())
+			if e.Name() == "1.txt" {
+				// b is read after a, so it sees the new file
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, "b", "2.txt"), nil, 0o600))
+			}
+		})
+		assert.Equal(t, []string{"1.txt", "2.txt"}, list)
+		assert.Equal(t, 1, stream.FromGlob(filepath.Join(dir, "*", "*.txt")).Limit(1).Count())
+	})
+}
@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+)
+
+// GroupBy collects the elements of s by key, groups are emitted in first-seen key order.
+func GroupBy[T any, K comparable](s Stream[T], keyFn function.Func[T, K]) Stream[collections.Entry[K, []T]] {
+	helper.RequireCanButNonNil(s)
+	helper.RequireCanButNonNil(keyFn)
+	return fromEach(func(down function.Consumer[collections.Entry[K, []T]], more func() bool) {
+		var keys []K
+		groups := make(map[K][]T)
+		forEachUpstream(s, more, func(t T) {
+			key := keyFn.Apply(t)
+			list, ok := groups[key]
+			if !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(list, t)
+		})
+		for _, key := range keys {
+			if !more() {
+				return
+			}
+			down(collections.EntryOf(key, groups[key]))
+		}
+	}, s)
+}
+
+// GroupAdjacentBy groups runs of consecutive elements sharing the same key.
+// Only the current run is buffered, a key seen again later starts a new group.
+func GroupAdjacentBy[T any, K comparable](s Stream[T], keyFn function.Func[T, K]) Stream[collections.Entry[K, []T]] {
+	helper.RequireCanButNonNil(s)
+	helper.RequireCanButNonNil(keyFn)
+	return fromEach(func(down function.Consumer[collections.Entry[K, []T]], more func() bool) {
+		var run []T
+		var runKey K
+		forEachUpstream(s, more, func(t T) {
+			key := keyFn.Apply(t)
+			if len(run) > 0 && key != runKey {
+				down(collections.EntryOf(runKey, run))
+				run = nil
+			}
+			runKey = key
+			run = append(run, t)
+		})
+		if len(run) > 0 && more() {
+			down(collections.EntryOf(runKey, run))
+		}
+	}, s)
+}
+
+// Chunk groups consecutive elements of s into slices of size elements, the
+// last one holding whatever is left.
+func Chunk[T any](s Stream[T], size int) Stream[[]T] {
+	helper.RequireCanButNonNil(s)
+	if size < 1 {
+		panic("chunk size must be positive")
+	}
+	return fromEach(func(down function.Consumer[[]T], more func() bool) {
+		var chunk []T
+		forEachUpstream(s, more, func(t T) {
+			chunk = append(chunk, t)
+			if len(chunk) == size {
+				down(chunk)
+				chunk = nil
+			}
+		})
+		if len(chunk) > 0 && more() {
+			down(chunk)
+		}
+	}, s)
+}
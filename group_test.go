@@ -0,0 +1,79 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/go-park/stream/support/collections"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupBy(t *testing.T) {
+	type line struct {
+		Session string
+		Msg     string
+	}
+	lines := []line{
+		{"b", "1"}, {"b", "2"}, {"a", "3"}, {"b", "4"}, {"c", "5"}, {"c", "6"},
+	}
+	session := func(l line) string { return l.Session }
+
+	t.Run("group", func(t *testing.T) {
+		list := stream.GroupBy(stream.From(lines...), session).ToSlice()
+		assert.Equal(t, []collections.Entry[string, []line]{
+			collections.EntryOf("b", []line{lines[0], lines[1], lines[3]}),
+			collections.EntryOf("a", []line{lines[2]}),
+			collections.EntryOf("c", []line{lines[4], lines[5]}),
+		}, list)
+	})
+
+	t.Run("adjacent", func(t *testing.T) {
+		list := stream.GroupAdjacentBy(stream.From(lines...), session).ToSlice()
+		assert.Equal(t, []collections.Entry[string, []line]{
+			collections.EntryOf("b", []line{lines[0], lines[1]}),
+			collections.EntryOf("a", []line{lines[2]}),
+			collections.EntryOf("b", []line{lines[3]}),
+			collections.EntryOf("c", []line{lines[4], lines[5]}),
+		}, list)
+	})
+
+	t.Run("adjacent-empty", func(t *testing.T) {
+		assert.Equal(t, 0, stream.GroupAdjacentBy(stream.From[line](), session).Count())
+	})
+
+	t.Run("group-empty", func(t *testing.T) {
+		assert.Equal(t, 0, stream.GroupBy(stream.From[line](), session).Count())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		first := func(l string) string { return l[:1] }
+		s := stream.GroupAdjacentBy(stream.FromLines(&failingReader{data: "a1\na2\nb1\n", err: errTransient}), first)
+		// the group read before the failure is still emitted
+		assert.Equal(t, []collections.Entry[string, []string]{
+			collections.EntryOf("a", []string{"a1", "a2"}),
+			collections.EntryOf("b", []string{"b1"}),
+		}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errTransient)
+	})
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		size int
+		want [][]int
+	}{
+		{"even", []int{1, 2, 3, 4}, 2, [][]int{{1, 2}, {3, 4}}},
+		{"rest", []int{1, 2, 3, 4, 5}, 2, [][]int{{1, 2}, {3, 4}, {5}}},
+		{"larger", []int{1, 2}, 5, [][]int{{1, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stream.Chunk(stream.From(tt.in...), tt.size).ToSlice())
+		})
+	}
+	assert.Equal(t, 0, stream.Chunk(stream.From[int](), 3).Count())
+	assert.Panics(t, func() { stream.Chunk(stream.From(1), 0) })
+	assert.Panics(t, func() { stream.Chunk(stream.From(1), -1) })
+}
@@ -0,0 +1,198 @@
+package stream
+
+import (
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+	"github.com/go-park/stream/support/optional"
+	"golang.org/x/exp/constraints"
+)
+
+// hashTable is the materialised build side of a hash join.
+type hashTable[R any, K comparable] struct {
+	rows    []R
+	keys    []K
+	index   map[K][]int
+	matched map[K]bool
+}
+
+func buildHashTable[R any, K comparable](right Stream[R], rkey function.Func[R, K]) *hashTable[R, K] {
+	table := &hashTable[R, K]{index: make(map[K][]int), matched: make(map[K]bool)}
+	right.Sequential().ForEach(func(r R) {
+		key := rkey.Apply(r)
+		table.index[key] = append(table.index[key], len(table.rows))
+		table.rows = append(table.rows, r)
+		table.keys = append(table.keys, key)
+	})
+	return table
+}
+
+func (table *hashTable[R, K]) probe(key K, fn function.Consumer[R]) bool {
+	list, ok := table.index[key]
+	if !ok {
+		return false
+	}
+	table.matched[key] = true
+	for _, i := range list {
+		fn(table.rows[i])
+	}
+	return true
+}
+
+func (table *hashTable[R, K]) unmatched(fn function.Consumer[R]) {
+	for i, r := range table.rows {
+		if !table.matched[table.keys[i]] {
+			fn(r)
+		}
+	}
+}
+
+// InnerJoin pairs every element of left with every element of right sharing the same key.
+// The right stream is the build side and is materialised into a map, left is streamed.
+func InnerJoin[L, R any, K comparable](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K]) Stream[collections.Pair[L, R]] {
+	return InnerJoinWith(left, right, lkey, rkey, collections.PairOf[L, R])
+}
+
+func InnerJoinWith[L, R, V any, K comparable](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K], result function.BiFunc[L, R, V]) Stream[V] {
+	helper.RequireCanButNonNil(left)
+	helper.RequireCanButNonNil(right)
+	helper.RequireCanButNonNil(lkey)
+	helper.RequireCanButNonNil(rkey)
+	helper.RequireCanButNonNil(result)
+	return fromEach(func(down function.Consumer[V], more func() bool) {
+		table := buildHashTable(right, rkey)
+		forEachUpstream(left, more, func(l L) {
+			table.probe(lkey.Apply(l), func(r R) { down(result.Apply(l, r)) })
+		})
+	}, left, right)
+}
+
+// LeftJoin is InnerJoin keeping the left elements without a match, paired with an empty value.
+func LeftJoin[L, R any, K comparable](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K]) Stream[collections.Pair[L, optional.Value[R]]] {
+	return LeftJoinWith(left, right, lkey, rkey, collections.PairOf[L, optional.Value[R]])
+}
+
+func LeftJoinWith[L, R, V any, K comparable](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K], result function.BiFunc[L, optional.Value[R], V]) Stream[V] {
+	helper.RequireCanButNonNil(left)
+	helper.RequireCanButNonNil(right)
+	helper.RequireCanButNonNil(lkey)
+	helper.RequireCanButNonNil(rkey)
+	helper.RequireCanButNonNil(result)
+	return fromEach(func(down function.Consumer[V], more func() bool) {
+		table := buildHashTable(right, rkey)
+		forEachUpstream(left, more, func(l L) {
+			found := table.probe(lkey.Apply(l), func(r R) {
+				down(result.Apply(l, optional.ValOf(r)))
+			})
+			if !found {
+				down(result.Apply(l, optional.EmptyVal[R]()))
+			}
+		})
+	}, left, right)
+}
+
+// FullOuterJoin is LeftJoin followed by the right elements that matched nothing, in their original order.
+func FullOuterJoin[L, R any, K comparable](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K]) Stream[collections.Pair[optional.Value[L], optional.Value[R]]] {
+	return FullOuterJoinWith(left, right, lkey, rkey, collections.PairOf[optional.Value[L], optional.Value[R]])
+}
+
+func FullOuterJoinWith[L, R, V any, K comparable](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K], result function.BiFunc[optional.Value[L], optional.Value[R], V]) Stream[V] {
+	helper.RequireCanButNonNil(left)
+	helper.RequireCanButNonNil(right)
+	helper.RequireCanButNonNil(lkey)
+	helper.RequireCanButNonNil(rkey)
+	helper.RequireCanButNonNil(result)
+	return fromEach(func(down function.Consumer[V], more func() bool) {
+		table := buildHashTable(right, rkey)
+		forEachUpstream(left, more, func(l L) {
+			found := table.probe(lkey.Apply(l), func(r R) {
+				down(result.Apply(optional.ValOf(l), optional.ValOf(r)))
+			})
+			if !found {
+				down(result.Apply(optional.ValOf(l), optional.EmptyVal[R]()))
+			}
+		})
+		table.unmatched(func(r R) {
+			if more() {
+				down(result.Apply(optional.EmptyVal[L](), optional.ValOf(r)))
+			}
+		})
+	}, left, right)
+}
+
+// SemiJoin keeps the left elements having at least one match in right, each emitted once.
+func SemiJoin[L, R any, K comparable](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K]) Stream[L] {
+	return filterByKeys(left, right, lkey, rkey, true)
+}
+
+// AntiJoin keeps the left elements having no match in right.
+func AntiJoin[L, R any, K comparable](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K]) Stream[L] {
+	return filterByKeys(left, right, lkey, rkey, false)
+}
+
+func filterByKeys[L, R any, K comparable](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K], keep bool) Stream[L] {
+	helper.RequireCanButNonNil(left)
+	helper.RequireCanButNonNil(right)
+	helper.RequireCanButNonNil(lkey)
+	helper.RequireCanButNonNil(rkey)
+	return fromEach(func(down function.Consumer[L], more func() bool) {
+		keys := make(map[K]helper.Empty)
+		right.Sequential().ForEach(func(r R) { keys[rkey.Apply(r)] = helper.Empty{} })
+		forEachUpstream(left, more, func(l L) {
+			if _, ok := keys[lkey.Apply(l)]; ok == keep {
+				down(l)
+			}
+		})
+	}, left, right)
+}
+
+// MergeJoin is an inner join of two streams already sorted ascending by key.
+// Neither side is materialised, only the run of right elements sharing the current key is buffered.
+func MergeJoin[L, R any, K constraints.Ordered](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K]) Stream[collections.Pair[L, R]] {
+	return MergeJoinWith(left, right, lkey, rkey, collections.PairOf[L, R])
+}
+
+func MergeJoinWith[L, R, V any, K constraints.Ordered](left Stream[L], right Stream[R],
+	lkey function.Func[L, K], rkey function.Func[R, K], result function.BiFunc[L, R, V]) Stream[V] {
+	helper.RequireCanButNonNil(left)
+	helper.RequireCanButNonNil(right)
+	helper.RequireCanButNonNil(lkey)
+	helper.RequireCanButNonNil(rkey)
+	helper.RequireCanButNonNil(result)
+	return fromEach(func(down function.Consumer[V], more func() bool) {
+		next, stop := pull(right)
+		defer right.Close()
+		defer stop()
+		r, ok := next()
+		var group []R
+		var groupKey K
+		grouped := false
+		forEachUpstream(left, more, func(l L) {
+			key := lkey.Apply(l)
+			if !grouped || groupKey != key {
+				group = group[:0]
+				for ok && rkey.Apply(r) < key {
+					r, ok = next()
+				}
+				for ok && rkey.Apply(r) == key {
+					group = append(group, r)
+					r, ok = next()
+				}
+				groupKey, grouped = key, true
+			}
+			for _, item := range group {
+				down(result.Apply(l, item))
+			}
+		})
+	}, left, right)
+}
@@ -0,0 +1,129 @@
+package stream_test
+
+import (
+	"math"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-park/stream"
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/optional"
+	"github.com/stretchr/testify/assert"
+)
+
+type event struct {
+	ID   int
+	User string
+}
+
+type user struct {
+	Name string
+	Team string
+}
+
+func TestJoin(t *testing.T) {
+	events := []event{{1, "foo"}, {2, "bar"}, {3, "baz"}, {4, "foo"}}
+	users := []user{{"foo", "a"}, {"bar", "b"}, {"qux", "c"}}
+	eventKey := func(e event) string { return e.User }
+	userKey := func(u user) string { return u.Name }
+
+	t.Run("inner", func(t *testing.T) {
+		list := stream.InnerJoinWith(stream.From(events...), stream.From(users...), eventKey, userKey,
+			func(e event, u user) string { return u.Team }).ToSlice()
+		assert.Equal(t, []string{"a", "b", "a"}, list)
+	})
+
+	t.Run("inner-pair", func(t *testing.T) {
+		list := stream.InnerJoin(stream.From(events...), stream.From(users...), eventKey, userKey).ToSlice()
+		assert.Equal(t, []collections.Pair[event, user]{
+			collections.PairOf(events[0], users[0]),
+			collections.PairOf(events[1], users[1]),
+			collections.PairOf(events[3], users[0]),
+		}, list)
+	})
+
+	t.Run("left", func(t *testing.T) {
+		list := stream.LeftJoinWith(stream.From(events...), stream.From(users...), eventKey, userKey,
+			func(e event, u optional.Value[user]) string {
+				if u.IsEmpty() {
+					return "-"
+				}
+				return u.Get().Team
+			}).ToSlice()
+		assert.Equal(t, []string{"a", "b", "-", "a"}, list)
+	})
+
+	t.Run("full-outer", func(t *testing.T) {
+		list := stream.FullOuterJoin(stream.From(events...), stream.From(users...), eventKey, userKey).ToSlice()
+		assert.Equal(t, 5, len(list))
+		assert.Equal(t, true, list[2].Right().IsEmpty())
+		assert.Equal(t, true, list[4].Left().IsEmpty())
+		assert.Equal(t, "qux", list[4].Right().Get().Name)
+	})
+
+	t.Run("semi", func(t *testing.T) {
+		list := stream.SemiJoin(stream.From(events...), stream.From(users...), eventKey, userKey).ToSlice()
+		assert.Equal(t, []event{events[0], events[1], events[3]}, list)
+	})
+
+	t.Run("anti", func(t *testing.T) {
+		list := stream.AntiJoin(stream.From(events...), stream.From(users...), eventKey, userKey).ToSlice()
+		assert.Equal(t, []event{events[2]}, list)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		left := []int{1, 2, 2, 4, 5, 7}
+		right := []int{2, 2, 3, 5, 6, 7, 7}
+		id := func(i int) int { return i }
+		list := stream.MergeJoinWith(stream.From(left...), stream.From(right...), id, id,
+			func(l, r int) int { return l * 10 }).ToSlice()
+		assert.Equal(t, []int{20, 20, 20, 20, 50, 70, 70}, list)
+	})
+
+	t.Run("merge-empty", func(t *testing.T) {
+		id := func(i int) int { return i }
+		list := stream.MergeJoin(stream.From(1, 2), stream.From[int](), id, id).ToSlice()
+		assert.Equal(t, 0, len(list))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, stream.InnerJoin(stream.From[event](), stream.From(users...), eventKey, userKey).ToSlice())
+		left := stream.LeftJoin(stream.From(events...), stream.From[user](), eventKey, userKey).ToSlice()
+		assert.Len(t, left, len(events))
+		assert.True(t, left[0].Right().IsEmpty())
+		assert.Len(t, stream.FullOuterJoin(stream.From[event](), stream.From(users...), eventKey, userKey).ToSlice(), len(users))
+		assert.Len(t, stream.AntiJoin(stream.From(events...), stream.From[user](), eventKey, userKey).ToSlice(), len(events))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		id := func(s string) string { return s }
+		for name, join := range map[string]func(l, r stream.Stream[string]) stream.Stream[string]{
+			"semi": func(l, r stream.Stream[string]) stream.Stream[string] { return stream.SemiJoin(l, r, id, id) },
+			"merge": func(l, r stream.Stream[string]) stream.Stream[string] {
+				return stream.MergeJoinWith(l, r, id, id, func(l, _ string) string { return l })
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				right := stream.FromLines(&failingReader{data: "a\nb\n", err: errTransient})
+				s := join(stream.From("a", "b", "c"), right)
+				assert.Equal(t, []string{"a", "b"}, s.ToSlice())
+				assert.ErrorIs(t, s.Err(), errTransient)
+			})
+		}
+	})
+
+	t.Run("merge-limit-infinite", func(t *testing.T) {
+		id := func(i int) int { return i }
+		before := runtime.NumGoroutine()
+		list := stream.MergeJoinWith(stream.Range(1, math.MaxInt), stream.RangeStep(2, math.MaxInt-1, 2), id, id,
+			func(l, r int) int { return l }).Limit(3).ToSlice()
+		assert.Equal(t, []int{2, 4, 6}, list)
+		// the goroutine reading the right side is gone
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+}
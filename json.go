@@ -0,0 +1,176 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/function"
+)
+
+// jsonIterator decodes one value at a time, either a sequence of values
+// or the elements of a single array.
+type jsonIterator[T any] struct {
+	dec     *json.Decoder
+	array   bool
+	started bool
+	next    T
+	ready   bool
+	done    bool
+	err     error
+}
+
+func (it *jsonIterator[T]) HasNext() bool {
+	if it.ready || it.done {
+		return it.ready
+	}
+	if it.array && !it.open() {
+		return false
+	}
+	if it.array && !it.dec.More() {
+		_, it.err = it.dec.Token()
+		it.done = true
+		return false
+	}
+	var t T
+	if err := it.dec.Decode(&t); err != nil {
+		if err != io.EOF || it.array {
+			it.err = err
+		}
+		it.done = true
+		return false
+	}
+	it.next, it.ready = t, true
+	return true
+}
+
+// open reads the opening bracket of the array, a null array is empty.
+func (it *jsonIterator[T]) open() bool {
+	if it.started {
+		return true
+	}
+	it.started = true
+	tok, err := it.dec.Token()
+	switch {
+	case err == io.EOF:
+		it.err = io.ErrUnexpectedEOF
+	case err != nil:
+		it.err = err
+	case tok == nil:
+	case tok != json.Delim('['):
+		it.err = fmt.Errorf("stream: json array expected, got %v", tok)
+	default:
+		return true
+	}
+	it.done = true
+	return false
+}
+
+func (it *jsonIterator[T]) Next() T {
+	var t T
+	if it.HasNext() {
+		t, it.ready = it.next, false
+	}
+	return t
+}
+
+func (it *jsonIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for it.HasNext() {
+		fn(it.Next())
+	}
+}
+
+func (it *jsonIterator[T]) Err() error {
+	return it.err
+}
+
+// FromJSONLines decodes the JSON values of r one after another, as written
+// one per line by ToJSONLines. A malformed value ends the stream and is
+// reported by Err.
+func FromJSONLines[T any](r io.Reader) Stream[T] {
+	return Builder[T]().iterator(&jsonIterator[T]{dec: json.NewDecoder(r)}).Build()
+}
+
+// FromJSONArray decodes the elements of the JSON array r holds one at a
+// time, without reading the whole array into memory. A malformed element
+// ends the stream and is reported by Err.
+func FromJSONArray[T any](r io.Reader) Stream[T] {
+	return Builder[T]().iterator(&jsonIterator[T]{dec: json.NewDecoder(r), array: true}).Build()
+}
+
+// forEachErr calls fn with the elements of s one at a time, closing s on
+// the first failure. A parallel s calls fn out of encounter order. It
+// returns the first error of fn or of s.
+func forEachErr[T any](s Stream[T], fn func(T) error) error {
+	helper.RequireCanButNonNil(s)
+	var (
+		mu  sync.Mutex
+		err error
+	)
+	s.ForEach(func(t T) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			return
+		}
+		if err = fn(t); err != nil {
+			s.Close()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return s.Err()
+}
+
+// writeEach writes the elements of s to w through write.
+func writeEach[T any](s Stream[T], w io.Writer, write func(*bufio.Writer, T) error) error {
+	bw := bufio.NewWriter(w)
+	err := forEachErr(s, func(t T) error { return write(bw, t) })
+	if flushErr := bw.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// ToJSONLines writes each element of s to w as JSON on a line of its own.
+// It returns the first error of encoding, writing or of s.
+func ToJSONLines[T any](s Stream[T], w io.Writer) error {
+	return writeEach(s, w, func(bw *bufio.Writer, t T) error {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		bw.Write(b)
+		return bw.WriteByte('\n')
+	})
+}
+
+// ToJSONArray writes the elements of s to w as a single JSON array, one
+// element at a time. It returns the first error of encoding, writing or
+// of s, in which case w holds a truncated array.
+func ToJSONArray[T any](s Stream[T], w io.Writer) error {
+	sep := byte('[')
+	err := writeEach(s, w, func(bw *bufio.Writer, t T) error {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		bw.WriteByte(sep)
+		sep = ','
+		_, err = bw.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if sep == '[' {
+		_, err = io.WriteString(w, "[]")
+		return err
+	}
+	_, err = io.WriteString(w, "]")
+	return err
+}
@@ -0,0 +1,91 @@
+package stream_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+type record struct {
+	ID   int    `json:"id"`
+	Kind string `json:"kind"`
+}
+
+func TestFromJSON(t *testing.T) {
+	t.Run("lines", func(t *testing.T) {
+		s := stream.FromJSONLines[record](strings.NewReader("{\"id\":1,\"kind\":\"a\"}\n\n{\"id\":2,\"kind\":\"b\"}\n"))
+		assert.Equal(t, []record{{1, "a"}, {2, "b"}}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+	t.Run("array", func(t *testing.T) {
+		s := stream.FromJSONArray[record](strings.NewReader(` [ {"id": 1, "kind": "a"}, {"id": 2} ] `))
+		assert.Equal(t, []record{{1, "a"}, {2, ""}}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+	t.Run("empty arrays", func(t *testing.T) {
+		for _, in := range []string{"[]", "null"} {
+			s := stream.FromJSONArray[record](strings.NewReader(in))
+			assert.Empty(t, s.ToSlice())
+			assert.NoError(t, s.Err())
+		}
+	})
+	t.Run("reads lazily", func(t *testing.T) {
+		r, w := io.Pipe()
+		go func() {
+			io.WriteString(w, "[1, 2, 3,")
+			// never completed, only the first two elements are needed
+		}()
+		assert.Equal(t, []int{1, 2}, stream.FromJSONArray[int](r).Limit(2).ToSlice())
+		w.Close()
+	})
+	t.Run("malformed", func(t *testing.T) {
+		s := stream.FromJSONLines[record](strings.NewReader("{\"id\":1}\n{\"id\":\"x\"}\n{\"id\":3}\n"))
+		assert.Equal(t, []record{{ID: 1}}, s.ToSlice())
+		var typeErr *json.UnmarshalTypeError
+		assert.True(t, errors.As(s.Err(), &typeErr))
+
+		s = stream.FromJSONArray[record](strings.NewReader(`[{"id":1},`))
+		assert.Equal(t, []record{{ID: 1}}, s.ToSlice())
+		assert.Error(t, s.Err())
+
+		s = stream.FromJSONArray[record](strings.NewReader(`{"id":1}`))
+		assert.Empty(t, s.ToSlice())
+		assert.Error(t, s.Err())
+	})
+}
+
+func TestToJSON(t *testing.T) {
+	records := []record{{1, "a"}, {2, "b"}}
+	t.Run("lines", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, stream.ToJSONLines(stream.From(records...), &buf))
+		assert.Equal(t, "{\"id\":1,\"kind\":\"a\"}\n{\"id\":2,\"kind\":\"b\"}\n", buf.String())
+		assert.Equal(t, records, stream.FromJSONLines[record](&buf).ToSlice())
+	})
+	t.Run("array", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, stream.ToJSONArray(stream.From(records...), &buf))
+		assert.Equal(t, `[{"id":1,"kind":"a"},{"id":2,"kind":"b"}]`, buf.String())
+		assert.Equal(t, records, stream.FromJSONArray[record](&buf).ToSlice())
+
+		buf.Reset()
+		assert.NoError(t, stream.ToJSONArray(stream.From[record](), &buf))
+		assert.Equal(t, "[]", buf.String())
+	})
+	t.Run("parallel", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, stream.ToJSONArray(stream.Range(1, 100).Parallel(), &buf))
+		got := stream.FromJSONArray[int](&buf)
+		assert.Equal(t, 5050, stream.Fold(got, 0, func(a, b int) int { return a + b }, func(a, b int) int { return a + b }))
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Error(t, stream.ToJSONLines(stream.From(make(chan int)), &buf))
+	})
+}
@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"math"
+
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/function"
+	"github.com/go-park/stream/support/optional"
+	"golang.org/x/exp/constraints"
+)
+
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+func Sum[T Number](s Stream[T]) T {
+	return Fold(s, 0, func(sum, t T) T { return sum + t }, func(i, j T) T { return i + j })
+}
+
+func SumBy[T any, N Number](s Stream[T], fn function.Func[T, N]) N {
+	helper.RequireCanButNonNil(fn)
+	return Fold(s, 0, func(sum N, t T) N { return sum + fn.Apply(t) }, func(i, j N) N { return i + j })
+}
+
+// Average returns the arithmetic mean of s, empty if s has no element.
+func Average[T Number](s Stream[T]) optional.Value[float64] {
+	stats := SummaryStatistics(s)
+	if stats.Count() == 0 {
+		return optional.EmptyVal[float64]()
+	}
+	return optional.ValOf(stats.Mean())
+}
+
+func SummaryStatistics[T Number](s Stream[T]) Statistics[T] {
+	return Fold(s, Statistics[T]{}, Statistics[T].Accept, Statistics[T].Combine)
+}
+
+// Statistics holds count, extremes, sum, mean and variance of numbers,
+// mean and variance are maintained with Welford's online algorithm.
+type Statistics[T Number] struct {
+	count    int
+	min, max T
+	sum      T
+	mean, m2 float64
+}
+
+// Accept returns the statistics with t added.
+func (st Statistics[T]) Accept(t T) Statistics[T] {
+	if st.count == 0 || t < st.min {
+		st.min = t
+	}
+	if st.count == 0 || t > st.max {
+		st.max = t
+	}
+	st.count++
+	st.sum += t
+	delta := float64(t) - st.mean
+	st.mean += delta / float64(st.count)
+	st.m2 += delta * (float64(t) - st.mean)
+	return st
+}
+
+// Combine merges the statistics of two disjoint sets of numbers.
+func (st Statistics[T]) Combine(other Statistics[T]) Statistics[T] {
+	if other.count == 0 {
+		return st
+	}
+	if st.count == 0 {
+		return other
+	}
+	count := st.count + other.count
+	delta := other.mean - st.mean
+	res := Statistics[T]{
+		count: count,
+		min:   st.min,
+		max:   st.max,
+		sum:   st.sum + other.sum,
+		mean:  st.mean + delta*float64(other.count)/float64(count),
+		m2:    st.m2 + other.m2 + delta*delta*float64(st.count)*float64(other.count)/float64(count),
+	}
+	if other.min < res.min {
+		res.min = other.min
+	}
+	if other.max > res.max {
+		res.max = other.max
+	}
+	return res
+}
+
+func (st Statistics[T]) Count() int {
+	return st.count
+}
+
+func (st Statistics[T]) Min() T {
+	return st.min
+}
+
+func (st Statistics[T]) Max() T {
+	return st.max
+}
+
+func (st Statistics[T]) Sum() T {
+	return st.sum
+}
+
+func (st Statistics[T]) Mean() float64 {
+	return st.mean
+}
+
+// Variance is the population variance, zero for less than two numbers.
+func (st Statistics[T]) Variance() float64 {
+	if st.count < 2 {
+		return 0
+	}
+	return st.m2 / float64(st.count)
+}
+
+// SampleVariance is the unbiased sample variance, zero for less than two numbers.
+func (st Statistics[T]) SampleVariance() float64 {
+	if st.count < 2 {
+		return 0
+	}
+	return st.m2 / float64(st.count-1)
+}
+
+func (st Statistics[T]) StdDev() float64 {
+	return math.Sqrt(st.Variance())
+}
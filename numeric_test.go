@@ -0,0 +1,70 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumeric(t *testing.T) {
+	list := []int{2, 4, 4, 4, 5, 5, 7, 9}
+
+	t.Run("sum", func(t *testing.T) {
+		assert.Equal(t, 40, stream.Sum(stream.From(list...)))
+		assert.Equal(t, 0, stream.Sum(stream.From[int]()))
+		assert.InDelta(t, 1.5, stream.Sum(stream.From(0.5, 1.0)), 1e-9)
+	})
+
+	t.Run("sumBy", func(t *testing.T) {
+		s := stream.From(P{Name: "foo", Age: 1}, P{Name: "bar", Age: 2})
+		assert.Equal(t, 3, stream.SumBy(s, func(p P) int { return p.Age }))
+	})
+
+	t.Run("average", func(t *testing.T) {
+		assert.Equal(t, 5.0, stream.Average(stream.From(list...)).Get())
+		assert.Equal(t, true, stream.Average(stream.From[int]()).IsEmpty())
+	})
+
+	t.Run("statistics", func(t *testing.T) {
+		stats := stream.SummaryStatistics(stream.From(list...))
+		assert.Equal(t, 8, stats.Count())
+		assert.Equal(t, 2, stats.Min())
+		assert.Equal(t, 9, stats.Max())
+		assert.Equal(t, 40, stats.Sum())
+		assert.InDelta(t, 5.0, stats.Mean(), 1e-9)
+		assert.InDelta(t, 4.0, stats.Variance(), 1e-9)
+		assert.InDelta(t, 2.0, stats.StdDev(), 1e-9)
+		assert.InDelta(t, 32.0/7, stats.SampleVariance(), 1e-9)
+	})
+
+	t.Run("statistics-edges", func(t *testing.T) {
+		empty := stream.SummaryStatistics(stream.From[int]())
+		assert.Equal(t, 0, empty.Count())
+		assert.Equal(t, 0, empty.Min())
+		assert.Equal(t, 0.0, empty.Variance())
+		one := stream.SummaryStatistics(stream.From(-3))
+		assert.Equal(t, -3, one.Min())
+		assert.Equal(t, -3, one.Max())
+		assert.Equal(t, 0.0, one.SampleVariance())
+		// an empty side leaves the other untouched
+		assert.Equal(t, one, one.Combine(empty))
+		assert.Equal(t, one, empty.Combine(one))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s := stream.CumulativeCount(stream.FromLines(&failingReader{data: "a\nb\n", err: errTransient}))
+		assert.Equal(t, 3, stream.Sum(s))
+		assert.ErrorIs(t, s.Err(), errTransient)
+	})
+
+	t.Run("combine", func(t *testing.T) {
+		left := stream.SummaryStatistics(stream.From(list[:3]...))
+		right := stream.SummaryStatistics(stream.From(list[3:]...))
+		all := left.Combine(right)
+		assert.Equal(t, stream.SummaryStatistics(stream.From(list...)).Count(), all.Count())
+		assert.InDelta(t, 4.0, all.Variance(), 1e-9)
+		assert.Equal(t, 2, all.Min())
+		assert.Equal(t, 9, all.Max())
+	})
+}
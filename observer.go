@@ -0,0 +1,322 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-park/stream/support/function"
+)
+
+// Observer is notified as elements flow through the stages of a pipeline.
+// Parallel pipelines call it from several goroutines at once.
+type Observer interface {
+	// OnStart is called before a run of the stage receives its first element.
+	OnStart(ev StageEvent)
+	// OnElement is called for every element the stage receives, with the number
+	// of elements it passed downstream and the time spent in the stage itself.
+	OnElement(ev StageEvent, emitted int, elapsed time.Duration)
+	// OnEnd closes every run, even a failed one. Barriers emit their
+	// elements at the end and report them here.
+	OnEnd(ev StageEvent, emitted int, elapsed time.Duration)
+	// OnError is called with the panic raised by a callback of the stage,
+	// before it propagates.
+	OnError(ev StageEvent, err error)
+}
+
+// StageEvent identifies a stage of a running pipeline.
+type StageEvent struct {
+	// Index is the position of the stage in the stages of Explain
+	Index int
+	Stage StageInfo
+	// Run identifies one pass of a goroutine through the stage, the stages
+	// of a segment drained together share it
+	Run   uint64
+	Chunk int
+	// Worker is set when the run happens on a goroutine started by the pipeline
+	Worker bool
+}
+
+var runs uint64
+
+func nextRun() uint64 {
+	return atomic.AddUint64(&runs, 1)
+}
+
+type multiObserver []Observer
+
+func (obs multiObserver) OnStart(ev StageEvent) {
+	for _, o := range obs {
+		o.OnStart(ev)
+	}
+}
+
+func (obs multiObserver) OnElement(ev StageEvent, emitted int, elapsed time.Duration) {
+	for _, o := range obs {
+		o.OnElement(ev, emitted, elapsed)
+	}
+}
+
+func (obs multiObserver) OnEnd(ev StageEvent, emitted int, elapsed time.Duration) {
+	for _, o := range obs {
+		o.OnEnd(ev, emitted, elapsed)
+	}
+}
+
+func (obs multiObserver) OnError(ev StageEvent, err error) {
+	for _, o := range obs {
+		o.OnError(ev, err)
+	}
+}
+
+func panicError(ev StageEvent, r any) error {
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("stream: stage %d %s: %w", ev.Index, ev.Stage.Op, err)
+	}
+	return fmt.Errorf("stream: stage %d %s: panic: %v", ev.Index, ev.Stage.Op, r)
+}
+
+// observe wraps the consumer of a streaming stage. A panic passing through
+// down was raised further downstream and is left for that stage to report.
+func observe[T any](obs Observer, ev StageEvent, s stage[T], down function.Consumer[T], stop func()) function.Consumer[T] {
+	emitted, pending := 0, false
+	var downstream time.Duration
+	op := s.wrap(func(t T) {
+		emitted++
+		pending = true
+		start := time.Now()
+		down(t)
+		downstream += time.Since(start)
+		pending = false
+	}, stop)
+	return func(t T) {
+		emitted, downstream = 0, 0
+		defer func() {
+			if r := recover(); r != nil {
+				if !pending {
+					obs.OnError(ev, panicError(ev, r))
+				}
+				panic(r)
+			}
+		}()
+		start := time.Now()
+		op(t)
+		obs.OnElement(ev, emitted, time.Since(start)-downstream)
+	}
+}
+
+// observeBarrier runs a barrier stage, timing its elements as they are
+// gathered and the work it does once the upstream is done.
+func observeBarrier[T any](obs Observer, ev StageEvent, s stage[T],
+	run func(fac func() function.Consumer[T])) []T {
+	obs.OnStart(ev)
+	var done time.Time
+	defer func() {
+		if r := recover(); r != nil {
+			// earlier panics have been reported where they were raised
+			if !done.IsZero() {
+				obs.OnError(ev, panicError(ev, r))
+			}
+			obs.OnEnd(ev, 0, 0)
+			panic(r)
+		}
+	}()
+	list := s.barrier(func(fac func() function.Consumer[T]) {
+		run(func() function.Consumer[T] {
+			op := fac()
+			return func(t T) {
+				defer func() {
+					if r := recover(); r != nil {
+						obs.OnError(ev, panicError(ev, r))
+						panic(r)
+					}
+				}()
+				start := time.Now()
+				op(t)
+				obs.OnElement(ev, 0, time.Since(start))
+			}
+		})
+		done = time.Now()
+	})
+	obs.OnEnd(ev, len(list), time.Since(done))
+	return list
+}
+
+// NopObserver ignores every event, embed it to implement only some of them.
+type NopObserver struct{}
+
+func (NopObserver) OnStart(StageEvent)                       {}
+func (NopObserver) OnElement(StageEvent, int, time.Duration) {}
+func (NopObserver) OnEnd(StageEvent, int, time.Duration)     {}
+func (NopObserver) OnError(StageEvent, error)                {}
+
+// StageCount is the number of elements a stage received and emitted.
+type StageCount struct {
+	Index   int
+	Stage   StageInfo
+	In, Out int
+}
+
+// CountingObserver counts the elements in and out of each stage.
+type CountingObserver struct {
+	NopObserver
+	mu     sync.Mutex
+	counts map[int]*StageCount
+}
+
+func NewCountingObserver() *CountingObserver {
+	return &CountingObserver{counts: make(map[int]*StageCount)}
+}
+
+func (c *CountingObserver) get(ev StageEvent) *StageCount {
+	count, ok := c.counts[ev.Index]
+	if !ok {
+		count = &StageCount{Index: ev.Index, Stage: ev.Stage}
+		c.counts[ev.Index] = count
+	}
+	return count
+}
+
+func (c *CountingObserver) OnElement(ev StageEvent, emitted int, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := c.get(ev)
+	count.In++
+	count.Out += emitted
+}
+
+func (c *CountingObserver) OnEnd(ev StageEvent, emitted int, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.get(ev).Out += emitted
+}
+
+// Counts returns the counts of the stages seen so far, in plan order.
+func (c *CountingObserver) Counts() []StageCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list := make([]StageCount, 0, len(c.counts))
+	for _, count := range c.counts {
+		list = append(list, *count)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Index < list[j].Index })
+	return list
+}
+
+// StageTiming is the time spent in the callbacks of a stage, summed over
+// its parallel runs.
+type StageTiming struct {
+	Index   int
+	Stage   StageInfo
+	Elapsed time.Duration
+}
+
+// TimingObserver measures the time spent in each stage, excluding the
+// stages downstream of it.
+type TimingObserver struct {
+	NopObserver
+	mu      sync.Mutex
+	timings map[int]*StageTiming
+}
+
+func NewTimingObserver() *TimingObserver {
+	return &TimingObserver{timings: make(map[int]*StageTiming)}
+}
+
+func (o *TimingObserver) add(ev StageEvent, elapsed time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	timing, ok := o.timings[ev.Index]
+	if !ok {
+		timing = &StageTiming{Index: ev.Index, Stage: ev.Stage}
+		o.timings[ev.Index] = timing
+	}
+	timing.Elapsed += elapsed
+}
+
+func (o *TimingObserver) OnElement(ev StageEvent, _ int, elapsed time.Duration) {
+	o.add(ev, elapsed)
+}
+
+func (o *TimingObserver) OnEnd(ev StageEvent, _ int, elapsed time.Duration) {
+	o.add(ev, elapsed)
+}
+
+// Timings returns the timings of the stages seen so far, in plan order.
+func (o *TimingObserver) Timings() []StageTiming {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	list := make([]StageTiming, 0, len(o.timings))
+	for _, timing := range o.timings {
+		list = append(list, *timing)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Index < list[j].Index })
+	return list
+}
+
+type regionKey struct {
+	run   uint64
+	index int
+}
+
+// TraceObserver wraps each run of a stage in a runtime/trace region, and
+// labels parallel workers with their chunk and stages for pprof.
+type TraceObserver struct {
+	NopObserver
+	ctx     context.Context
+	mu      sync.Mutex
+	regions map[regionKey]*trace.Region
+	labels  map[uint64]context.Context
+}
+
+// NewTraceObserver creates the regions and labels under ctx, a nil ctx is context.Background.
+func NewTraceObserver(ctx context.Context) *TraceObserver {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &TraceObserver{
+		ctx:     ctx,
+		regions: make(map[regionKey]*trace.Region),
+		labels:  make(map[uint64]context.Context),
+	}
+}
+
+func (o *TraceObserver) OnStart(ev StageEvent) {
+	name := ev.Stage.Op
+	if ev.Stage.Label != "" {
+		name += " " + ev.Stage.Label
+	}
+	region := trace.StartRegion(o.ctx, name)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.regions[regionKey{run: ev.Run, index: ev.Index}] = region
+	if !ev.Worker {
+		// the caller's goroutine keeps its own labels
+		return
+	}
+	ctx, ok := o.labels[ev.Run]
+	if !ok {
+		ctx = pprof.WithLabels(o.ctx, pprof.Labels("stream.chunk", strconv.Itoa(ev.Chunk)))
+	}
+	ctx = pprof.WithLabels(ctx, pprof.Labels(fmt.Sprintf("stream.stage.%d", ev.Index), ev.Stage.Op))
+	o.labels[ev.Run] = ctx
+	pprof.SetGoroutineLabels(ctx)
+}
+
+func (o *TraceObserver) OnEnd(ev StageEvent, _ int, _ time.Duration) {
+	key := regionKey{run: ev.Run, index: ev.Index}
+	o.mu.Lock()
+	region := o.regions[key]
+	delete(o.regions, key)
+	delete(o.labels, ev.Run)
+	o.mu.Unlock()
+	if region != nil {
+		region.End()
+	}
+}
@@ -0,0 +1,130 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime/trace"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	stream.NopObserver
+	mu     sync.Mutex
+	starts int
+	ends   int
+	errs   []error
+}
+
+func (o *recordingObserver) OnStart(stream.StageEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.starts++
+}
+
+func (o *recordingObserver) OnEnd(stream.StageEvent, int, time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ends++
+}
+
+func (o *recordingObserver) OnError(_ stream.StageEvent, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errs = append(o.errs, err)
+}
+
+func TestCountingObserver(t *testing.T) {
+	list := []int{5, 1, 4, 2, 3, 6, 8, 7}
+	for _, parallel := range []bool{false, true} {
+		t.Run("parallel "+strconv.FormatBool(parallel), func(t *testing.T) {
+			counter := stream.NewCountingObserver()
+			s := stream.Builder[int]().Source(list...).Observe(counter).Build()
+			if parallel {
+				s = s.Parallel()
+			}
+			res := s.Filter(func(i int) bool { return i%2 == 0 }).
+				Sort(func(i, j int) bool { return i < j }).
+				Skip(1).
+				MapToString(strconv.Itoa).
+				Map(func(s string) string { return s + "!" }).
+				ToSlice()
+			assert.Equal(t, []string{"4!", "6!", "8!"}, res)
+			var got []stream.StageCount
+			for _, c := range counter.Counts() {
+				got = append(got, stream.StageCount{Index: c.Index, In: c.In, Out: c.Out})
+			}
+			assert.Equal(t, []stream.StageCount{
+				{Index: 0, In: 8, Out: 4},
+				{Index: 1, In: 4, Out: 4},
+				{Index: 2, In: 4, Out: 3},
+				{Index: 4, In: 3, Out: 3},
+			}, got)
+		})
+	}
+}
+
+func TestTimingObserver(t *testing.T) {
+	timer := stream.NewTimingObserver()
+	stream.Builder[int]().Source(1, 2, 3).Observe(timer).Build().
+		Map(func(i int) int {
+			time.Sleep(5 * time.Millisecond)
+			return i
+		}).
+		Skip(0).
+		ForEach(func(int) { time.Sleep(5 * time.Millisecond) })
+	timings := timer.Timings()
+	assert.Len(t, timings, 2)
+	assert.Equal(t, "Map", timings[0].Stage.Op)
+	assert.GreaterOrEqual(t, timings[0].Elapsed, 15*time.Millisecond)
+	// the time spent downstream is not accounted to the stage
+	assert.Less(t, timings[1].Elapsed, 15*time.Millisecond)
+}
+
+func TestObserverError(t *testing.T) {
+	boom := errors.New("boom")
+	t.Run("streaming", func(t *testing.T) {
+		obs := &recordingObserver{}
+		s := stream.Builder[int]().Source(1, 2, 3).Observe(obs).Build().
+			Map(func(i int) int {
+				if i == 2 {
+					panic(boom)
+				}
+				return i
+			}).
+			Filter(func(i int) bool { return true })
+		assert.PanicsWithValue(t, boom, func() { s.ToSlice() })
+		assert.Len(t, obs.errs, 1)
+		assert.ErrorIs(t, obs.errs[0], boom)
+		assert.Equal(t, obs.starts, obs.ends)
+	})
+	t.Run("barrier", func(t *testing.T) {
+		obs := &recordingObserver{}
+		s := stream.Builder[int]().Source(1, 2, 3).Observe(obs).Build().
+			Filter(func(i int) bool { return true }).
+			Sort(func(i, j int) bool { panic("less") })
+		assert.PanicsWithValue(t, "less", func() { s.ToSlice() })
+		assert.Len(t, obs.errs, 1)
+		assert.EqualError(t, obs.errs[0], "stream: stage 1 Sort: panic: less")
+		assert.Equal(t, obs.starts, obs.ends)
+	})
+}
+
+func TestTraceObserver(t *testing.T) {
+	if err := trace.Start(io.Discard); err != nil {
+		t.Skip(err)
+	}
+	defer trace.Stop()
+	obs := stream.NewTraceObserver(context.Background())
+	res := stream.Builder[int]().Source(3, 1, 2).Observe(obs, stream.NewCountingObserver()).Build().Parallel().
+		Map(func(i int) int { return i * 2 }).
+		Sort(func(i, j int) bool { return i < j }).
+		ToSlice()
+	assert.Equal(t, []int{2, 4, 6}, res)
+}
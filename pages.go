@@ -0,0 +1,190 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+)
+
+// PageOptions configures FromPages. The zero value fetches a page once the
+// previous one is consumed and fails the stream on the first error.
+type PageOptions struct {
+	// Prefetch is the number of pages fetched ahead of the stream, in the
+	// background, 0 fetches them in turn.
+	Prefetch int
+	// Retries is the number of times a failed fetch is tried again.
+	Retries int
+	// Backoff is the wait before the n-th retry, counting from 1. It is
+	// ExponentialBackoff(100*time.Millisecond, 30*time.Second) when nil.
+	Backoff func(n int) time.Duration
+	// Retryable tells the transient errors worth a retry, all when nil.
+	Retryable func(error) bool
+}
+
+// ExponentialBackoff waits base before the first retry and twice as long
+// before each one after, up to max.
+func ExponentialBackoff(base, max time.Duration) func(n int) time.Duration {
+	return func(n int) time.Duration {
+		d := base
+		for i := 1; i < n && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// page is a fetched page, or the error that ended the fetching.
+type page[T any] struct {
+	items []T
+	next  string
+	err   error
+}
+
+// pageIterator walks the pages from the empty cursor until one has no
+// next cursor. Closing it cancels the fetch in flight.
+type pageIterator[T any] struct {
+	// parent is the context of the caller, ctx the one of the stream
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	fetch  func(ctx context.Context, cursor string) ([]T, string, error)
+	opts   PageOptions
+	cursor string
+	last   bool
+	items  []T
+	// pages receives the prefetched pages once the first one is needed
+	pages chan page[T]
+	err   error
+}
+
+func (it *pageIterator[T]) HasNext() bool {
+	for len(it.items) == 0 {
+		if it.last {
+			return false
+		}
+		p := it.nextPage()
+		if p.err != nil {
+			// a fetch failing because the stream was closed is no error
+			if it.ctx.Err() == nil || it.parent.Err() != nil {
+				it.err = p.err
+			}
+			p.next = ""
+		}
+		it.items, it.cursor, it.last = p.items, p.next, p.next == ""
+		if it.last {
+			it.cancel()
+		}
+	}
+	return true
+}
+
+func (it *pageIterator[T]) nextPage() page[T] {
+	if it.opts.Prefetch <= 0 {
+		return it.get(it.cursor)
+	}
+	if it.pages == nil {
+		// the fetcher holds one page while it waits for the channel
+		it.pages = make(chan page[T], it.opts.Prefetch-1)
+		go it.prefetch(it.cursor)
+	}
+	if p, ok := <-it.pages; ok {
+		return p
+	}
+	return page[T]{err: it.ctx.Err()}
+}
+
+func (it *pageIterator[T]) prefetch(cursor string) {
+	defer close(it.pages)
+	for {
+		p := it.get(cursor)
+		select {
+		case it.pages <- p:
+		case <-it.ctx.Done():
+			return
+		}
+		if p.err != nil || p.next == "" {
+			return
+		}
+		cursor = p.next
+	}
+}
+
+// get fetches the page at cursor, retrying as opts allow.
+func (it *pageIterator[T]) get(cursor string) page[T] {
+	for n := 1; ; n++ {
+		items, next, err := it.fetch(it.ctx, cursor)
+		if err == nil {
+			return page[T]{items: items, next: next}
+		}
+		if it.ctx.Err() != nil {
+			return page[T]{err: it.ctx.Err()}
+		}
+		if n > it.opts.Retries || it.opts.Retryable != nil && !it.opts.Retryable(err) {
+			return page[T]{err: fmt.Errorf("stream: fetching page %q: %w", cursor, err)}
+		}
+		backoff := it.opts.Backoff
+		if backoff == nil {
+			backoff = ExponentialBackoff(100*time.Millisecond, 30*time.Second)
+		}
+		timer := time.NewTimer(backoff(n))
+		select {
+		case <-timer.C:
+		case <-it.ctx.Done():
+			timer.Stop()
+			return page[T]{err: it.ctx.Err()}
+		}
+	}
+}
+
+func (it *pageIterator[T]) Next() T {
+	var t T
+	if it.HasNext() {
+		t, it.items = it.items[0], it.items[1:]
+	}
+	return t
+}
+
+func (it *pageIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for it.HasNext() {
+		fn(it.Next())
+	}
+}
+
+// Err also reports the end of the context of the caller before the last
+// page, which stops the stream wherever it is.
+func (it *pageIterator[T]) Err() error {
+	if it.err == nil && !it.last {
+		return it.parent.Err()
+	}
+	return it.err
+}
+
+func (it *pageIterator[T]) Close() error {
+	it.cancel()
+	return nil
+}
+
+// FromPages yields the items of the pages fetch returns, starting from the
+// empty cursor and following the next cursors until an empty one. Pages
+// are fetched as the stream needs them, or ahead of it as opts tells, and
+// no more once it is done, closed or ctx is. A fetch failing past its
+// retries ends the stream and is reported by Err, as is the end of ctx.
+func FromPages[T any](ctx context.Context, fetch func(ctx context.Context, cursor string) ([]T, string, error),
+	opts PageOptions) Stream[T] {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b := Builder[T]()
+	b.ctx = ctx
+	b.open = func(stream context.Context) collections.Iterator[T] {
+		stream, cancel := context.WithCancel(stream)
+		return &pageIterator[T]{parent: ctx, ctx: stream, cancel: cancel, fetch: fetch, opts: opts}
+	}
+	return b.Build()
+}
@@ -0,0 +1,162 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+// pager serves pages of size items out of total, the cursor being the
+// index of the first item of the page.
+type pager struct {
+	size, total int
+	fetched     int32
+	// fail counts down failing fetches
+	fail int32
+}
+
+var errTransient = errors.New("transient")
+
+func (p *pager) fetch(ctx context.Context, cursor string) ([]int, string, error) {
+	atomic.AddInt32(&p.fetched, 1)
+	if atomic.AddInt32(&p.fail, -1) >= 0 {
+		return nil, "", errTransient
+	}
+	start := 0
+	if cursor != "" {
+		start, _ = strconv.Atoi(cursor)
+	}
+	var items []int
+	for i := start; i < start+p.size && i < p.total; i++ {
+		items = append(items, i)
+	}
+	next := ""
+	if start+p.size < p.total {
+		next = strconv.Itoa(start + p.size)
+	}
+	return items, next, nil
+}
+
+func TestFromPages(t *testing.T) {
+	ctx := context.Background()
+	for _, prefetch := range []int{0, 1, 3} {
+		t.Run("prefetch "+strconv.Itoa(prefetch), func(t *testing.T) {
+			p := &pager{size: 3, total: 10}
+			s := stream.FromPages(ctx, p.fetch, stream.PageOptions{Prefetch: prefetch})
+			assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, s.ToSlice())
+			assert.NoError(t, s.Err())
+			assert.Equal(t, int32(4), atomic.LoadInt32(&p.fetched))
+		})
+	}
+	t.Run("lazy", func(t *testing.T) {
+		p := &pager{size: 3, total: 100}
+		s := stream.FromPages(ctx, p.fetch, stream.PageOptions{})
+		assert.Equal(t, int32(0), atomic.LoadInt32(&p.fetched))
+		assert.Equal(t, []int{0, 1, 2, 3}, s.Limit(4).ToSlice())
+		assert.Equal(t, int32(2), atomic.LoadInt32(&p.fetched))
+	})
+	t.Run("prefetch overlaps", func(t *testing.T) {
+		started := make(chan string, 10)
+		fetch := func(ctx context.Context, cursor string) ([]string, string, error) {
+			started <- cursor
+			if cursor == "" {
+				return []string{"a"}, "b", nil
+			}
+			return []string{cursor}, "", nil
+		}
+		var overlapped bool
+		s := stream.FromPages(ctx, fetch, stream.PageOptions{Prefetch: 1})
+		list := s.Filter(func(v string) bool {
+			if v == "a" {
+				<-started
+				select {
+				case <-started:
+					overlapped = true
+				case <-time.After(time.Second):
+				}
+			}
+			return true
+		}).ToSlice()
+		assert.Equal(t, []string{"a", "b"}, list)
+		assert.True(t, overlapped)
+	})
+	t.Run("stops prefetching", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		fetch := func(ctx context.Context, cursor string) ([]int, string, error) {
+			if cursor == "" {
+				return []int{1, 2}, "next", nil
+			}
+			<-ctx.Done()
+			close(cancelled)
+			return nil, "", ctx.Err()
+		}
+		s := stream.FromPages(ctx, fetch, stream.PageOptions{Prefetch: 2})
+		assert.Equal(t, []int{1}, s.Limit(1).ToSlice())
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("the prefetch was not cancelled")
+		}
+		assert.NoError(t, s.Err())
+	})
+	t.Run("retries", func(t *testing.T) {
+		p := &pager{size: 5, total: 10, fail: 2}
+		s := stream.FromPages(ctx, p.fetch, stream.PageOptions{
+			Retries: 2,
+			Backoff: stream.ExponentialBackoff(time.Millisecond, 2*time.Millisecond),
+		})
+		assert.Equal(t, 10, s.Count())
+		assert.NoError(t, s.Err())
+		assert.Equal(t, int32(4), atomic.LoadInt32(&p.fetched))
+	})
+	t.Run("retries exhausted", func(t *testing.T) {
+		p := &pager{size: 5, total: 10, fail: 3}
+		s := stream.FromPages(ctx, p.fetch, stream.PageOptions{
+			Prefetch: 1,
+			Retries:  2,
+			Backoff:  func(int) time.Duration { return 0 },
+		})
+		assert.Empty(t, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errTransient)
+	})
+	t.Run("not retryable", func(t *testing.T) {
+		p := &pager{size: 5, total: 10, fail: 1}
+		s := stream.FromPages(ctx, p.fetch, stream.PageOptions{
+			Retries:   5,
+			Retryable: func(err error) bool { return !errors.Is(err, errTransient) },
+		})
+		assert.Empty(t, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errTransient)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&p.fetched))
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		p := &pager{size: 2, total: 10}
+		s := stream.FromPages(cctx, p.fetch, stream.PageOptions{}).Filter(func(i int) bool {
+			if i == 2 {
+				cancel()
+			}
+			return true
+		})
+		assert.Equal(t, []int{0, 1, 2}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), context.Canceled)
+	})
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := stream.ExponentialBackoff(100*time.Millisecond, time.Second)
+	var waits []time.Duration
+	for n := 1; n <= 6; n++ {
+		waits = append(waits, backoff(n))
+	}
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second,
+	}, waits)
+}
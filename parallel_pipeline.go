@@ -17,6 +17,22 @@ func GetParallelism() int {
 	return parallelism
 }
 
+// split cuts list into at most n contiguous chunks of near equal size,
+// so that combining per chunk results in order keeps the encounter order.
+func split[T any](list []T, n int) [][]T {
+	if n < 1 {
+		n = 1
+	}
+	if n > len(list) {
+		n = len(list)
+	}
+	chunks := make([][]T, 0, n)
+	for i := 0; i < n; i++ {
+		chunks = append(chunks, list[i*len(list)/n:(i+1)*len(list)/n])
+	}
+	return chunks
+}
+
 type (
 	ParallelPipline[T any] struct {
 		sp SimplePipline[T]
@@ -31,6 +47,18 @@ func (p ParallelPipline[T]) Count() int {
 	return p.sp.Count()
 }
 
+func (p ParallelPipline[T]) done() <-chan struct{} {
+	return p.sp.done()
+}
+
+func (p ParallelPipline[T]) Err() error {
+	return p.sp.Err()
+}
+
+func (p ParallelPipline[T]) Explain() Plan {
+	return p.sp.Explain()
+}
+
 func (p ParallelPipline[T]) ToSlice() []T {
 	return p.sp.ToSlice()
 }
@@ -119,7 +147,11 @@ func (p ParallelPipline[T]) Map(mapper function.Func[T, T]) Stream[T] {
 }
 
 func (p ParallelPipline[T]) Reduce(acc function.BiFunc[T, T, T]) optional.Value[T] {
-	return optional.EmptyVal[T]()
+	return p.sp.Reduce(acc)
+}
+
+func (p ParallelPipline[T]) forEachChunk(fac func() function.Consumer[T]) {
+	p.sp.forEachChunk(fac)
 }
 
 func (p ParallelPipline[T]) MapToAny(mapper function.Func[T, any]) Stream[any] {
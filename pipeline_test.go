@@ -66,6 +66,9 @@ func TestPipline(t *testing.T) {
 	t.Run("fast-sequential", func(t *testing.T) {
 		testPipline(t, false, false)
 	})
+	t.Run("fast-parallel", func(t *testing.T) {
+		testPipline(t, false, true)
+	})
 	t.Run("simple-sequential", func(t *testing.T) {
 		testPipline(t, true, false)
 	})
@@ -384,6 +387,68 @@ func testPipline(t *testing.T, simple, parallel bool) {
 	}
 }
 
+func TestMapToStops(t *testing.T) {
+	strs := stream.Range(1, math.MaxInt).MapToString(func(i int) string { return fmt.Sprint(i) }).Limit(2)
+	assert.Equal(t, []string{"1", "2"}, strs.ToSlice())
+
+	mapped := 0
+	lens := stream.Range(1, math.MaxInt).
+		Map(func(i int) int {
+			mapped++
+			return i
+		}).
+		MapToString(func(i int) string { return fmt.Sprint(i * 10) }).
+		MapToInt(func(s string) int { return len(s) }).
+		Limit(3).
+		Sort(func(i, j int) bool { return i > j })
+	assert.Equal(t, []int{2, 2, 2}, lens.ToSlice())
+	assert.Equal(t, 3, mapped)
+
+	ints := stream.Range(1, math.MaxInt).MapToInt(func(i int) int { return -i })
+	assert.Equal(t, -1, ints.FindAny().Get())
+}
+
+// TestShortCircuit checks that a short-circuiting operation after a stage
+// stops the infinite source the stage reads.
+func TestShortCircuit(t *testing.T) {
+	infinite := func() stream.Stream[int] { return stream.Range(1, math.MaxInt) }
+	id := func(i int) int { return i }
+	mod := func(i int) int { return i % 3 }
+	tests := map[string]func() int{
+		"GroupAdjacentBy": func() int {
+			return stream.GroupAdjacentBy(infinite(), func(i int) int { return i / 2 }).Limit(3).Count()
+		},
+		"Chunk":      func() int { return stream.Chunk(infinite(), 2).Limit(3).Count() },
+		"RunningSum": func() int { return stream.RunningSum(infinite()).Limit(3).Count() },
+		"RunningMax": func() int { return stream.RunningMax(infinite()).Limit(3).Count() },
+		"ScanLeft": func() int {
+			return stream.ScanLeft(infinite(), 0, func(r, i int) int { return r + i }).Limit(3).Count()
+		},
+		"InnerJoin":     func() int { return stream.InnerJoin(infinite(), stream.From(1, 2), mod, id).Limit(3).Count() },
+		"LeftJoin":      func() int { return stream.LeftJoin(infinite(), stream.From(1), mod, id).Limit(3).Count() },
+		"FullOuterJoin": func() int { return stream.FullOuterJoin(infinite(), stream.From(1), mod, id).Limit(3).Count() },
+		"SemiJoin":      func() int { return stream.SemiJoin(infinite(), stream.From(1), mod, id).Limit(3).Count() },
+		"AntiJoin":      func() int { return stream.AntiJoin(infinite(), stream.From(1), mod, id).Limit(3).Count() },
+		"MergeJoin": func() int {
+			return stream.MergeJoin(infinite(), stream.RangeStep(2, math.MaxInt-1, 2), id, id).Limit(3).Count()
+		},
+		"SampleFraction": func() int { return stream.SampleFraction(infinite(), 0.5, nil).Limit(3).Count() },
+		"DistinctApprox": func() int { return stream.DistinctApprox(infinite(), 100, 0.001).Limit(3).Count() },
+		"MapToString": func() int {
+			return infinite().MapToString(func(i int) string { return fmt.Sprint(i) }).Limit(3).Count()
+		},
+		"Format": func() int {
+			s, _ := stream.Format(infinite(), "{{.}}")
+			return s.Limit(3).Count()
+		},
+	}
+	for name, count := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, 3, count())
+		})
+	}
+}
+
 func BenchmarkPipeline(b *testing.B) {
 	var slice []int
 	for i := range make([]struct{}, 1000) {
@@ -0,0 +1,400 @@
+package stream
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+)
+
+// Plan is the form a pipeline runs in once the optimiser has rewritten it.
+type Plan struct {
+	Engine string
+	// Source holds the characteristics of the source, zero if it has none
+	Source collections.Characteristics
+	// Parallelism is the number of chunks the source is split into
+	Parallelism int
+	Stages      []StageInfo
+	// Rewrites explains every change the optimiser made, in the order it made them
+	Rewrites []string
+}
+
+// StageInfo describes an intermediate operation of a Plan.
+type StageInfo struct {
+	Op    string
+	Label string
+	// Stateful stages keep state across elements, a Barrier waits for its whole input
+	Stateful     bool
+	Barrier      bool
+	ShortCircuit bool
+	// Parallelism is the number of goroutines feeding elements through the stage
+	Parallelism int
+}
+
+func (info StageInfo) String() string {
+	s := info.Op
+	if info.Label != "" {
+		s += fmt.Sprintf(" %q", info.Label)
+	}
+	var flags []string
+	if info.Stateful {
+		flags = append(flags, "stateful")
+	}
+	if info.Barrier {
+		flags = append(flags, "barrier")
+	}
+	if info.ShortCircuit {
+		flags = append(flags, "short-circuit")
+	}
+	if info.Parallelism > 1 {
+		flags = append(flags, fmt.Sprintf("x%d", info.Parallelism))
+	}
+	if len(flags) > 0 {
+		s += " [" + strings.Join(flags, " ") + "]"
+	}
+	return s
+}
+
+func (pl Plan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s engine, parallelism %d\n", pl.Engine, pl.Parallelism)
+	fmt.Fprintf(&b, "source [%s]\n", pl.Source)
+	for i, info := range pl.Stages {
+		fmt.Fprintf(&b, "  %d. %s\n", i+1, info)
+	}
+	if len(pl.Rewrites) > 0 {
+		b.WriteString("rewrites:\n")
+		for _, r := range pl.Rewrites {
+			fmt.Fprintf(&b, "  %s\n", r)
+		}
+	}
+	return b.String()
+}
+
+// DOT renders the plan as a Graphviz digraph, barriers are drawn filled.
+func (pl Plan) DOT() string {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace
+	var b strings.Builder
+	b.WriteString("digraph plan {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	fmt.Fprintf(&b, "\tsource [label=\"%s engine\\n%s\", shape=ellipse];\n", quote(pl.Engine), quote(pl.Source.String()))
+	prev := "source"
+	for i, info := range pl.Stages {
+		label := info.Op
+		if info.Label != "" {
+			label += "\n" + info.Label
+		}
+		attrs := ""
+		if info.Barrier {
+			attrs = ", style=filled, fillcolor=lightgrey"
+		}
+		if info.ShortCircuit {
+			attrs += ", peripheries=2"
+		}
+		id := fmt.Sprintf("s%d", i+1)
+		fmt.Fprintf(&b, "\t%s [label=\"%s\"%s];\n", id, quote(label), attrs)
+		if info.Parallelism > 1 {
+			fmt.Fprintf(&b, "\t%s -> %s [label=\"x%d\"];\n", prev, id, info.Parallelism)
+		} else {
+			fmt.Fprintf(&b, "\t%s -> %s;\n", prev, id)
+		}
+		prev = id
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Explain returns the plan p would run with, without running it.
+// A pipeline made by MapTo* continues the plan of the one it maps.
+func (p *FastPipline[T]) Explain() Plan {
+	pl := p.optimise()
+	res := Plan{Engine: "fast", Parallelism: 1}
+	if p.parallel {
+		res.Parallelism = GetParallelism()
+	}
+	if p.from != nil {
+		res = p.from.Explain()
+		res.Stages = append(res.Stages, StageInfo{
+			Op:          p.mapName,
+			Barrier:     p.parallel,
+			Parallelism: res.Parallelism,
+		})
+	} else if sp, ok := p.source.(collections.Spliterator[T]); ok {
+		res.Source = sp.Characteristics()
+	}
+	for _, s := range pl.stages {
+		res.Stages = append(res.Stages, s.info(p.parallel))
+	}
+	res.Rewrites = append(res.Rewrites, pl.rewrites...)
+	return res
+}
+
+// info derives the metadata of s from its kind. Limit, Skip and Distinct
+// become barriers when parallel, and barriers run on a single goroutine
+// except TopK, which selects within each chunk first.
+func (s stage[T]) info(parallel bool) StageInfo {
+	info := StageInfo{Op: s.name, Label: s.label, Parallelism: 1}
+	switch s.op {
+	case opLimit:
+		info.Stateful, info.ShortCircuit, info.Barrier = true, true, parallel
+	case opSkip, opDistinct:
+		info.Stateful, info.Barrier = true, parallel
+	case opSort, opReverse, opTopK:
+		info.Stateful, info.Barrier = true, true
+	}
+	if parallel && (!info.Barrier || s.op == opTopK) {
+		info.Parallelism = GetParallelism()
+	}
+	return info
+}
+
+// Label names the last stage added to s, the name shows up in its plan.
+// It has no effect on engines other than the default one.
+func Label[T any](s Stream[T], label string) Stream[T] {
+	if p, ok := s.(*FastPipline[T]); ok && len(p.stages) > 0 {
+		p.stages[len(p.stages)-1].label = label
+	}
+	return s
+}
+
+type plan[T any] struct {
+	source collections.Iterator[T]
+	// truncate is the number of elements the source is cut to, -1 if it is not
+	truncate int
+	stages   []stage[T]
+	rewrites []string
+}
+
+// size returns the number of elements the plan yields when it is known upfront.
+func (pl plan[T]) size() (int, bool) {
+	sp, ok := pl.source.(collections.Spliterator[T])
+	if !ok || !sp.Characteristics().Has(collections.SIZED) {
+		return 0, false
+	}
+	for _, s := range pl.stages {
+		if s.op != opMap && s.op != opSort && s.op != opReverse {
+			return 0, false
+		}
+	}
+	size := sp.EstimateSize()
+	if pl.truncate >= 0 && pl.truncate < size {
+		size = pl.truncate
+	}
+	return size, true
+}
+
+func (pl *plan[T]) rewrite(format string, args ...any) {
+	pl.rewrites = append(pl.rewrites, fmt.Sprintf(format, args...))
+}
+
+// optimise rewrites the stages of p into an equivalent but cheaper plan,
+// p itself is left untouched.
+func (p *FastPipline[T]) optimise() plan[T] {
+	pl := plan[T]{source: p.source, truncate: -1}
+	pl.elide(p.stages)
+	pl.pushLimit()
+	pl.topK()
+	pl.fuse()
+	return pl
+}
+
+// elide drops the natural Sort and Distinct the source characteristics
+// already guarantee, tracking how each stage changes them.
+func (pl *plan[T]) elide(stages []stage[T]) {
+	var flags collections.Characteristics
+	if sp, ok := pl.source.(collections.Spliterator[T]); ok {
+		flags = sp.Characteristics()
+	}
+	for _, s := range stages {
+		switch {
+		case s.op == opSort && s.natural && flags.Has(collections.SORTED):
+			pl.rewrite("dropped Sort, the input is already SORTED")
+			continue
+		case s.op == opDistinct && s.natural && flags.Has(collections.DISTINCT):
+			pl.rewrite("dropped Distinct, the input is already DISTINCT")
+			continue
+		}
+		pl.stages = append(pl.stages, s)
+		switch s.op {
+		case opFilter, opLimit, opSkip:
+			flags &^= collections.SIZED
+		case opMap:
+			flags &^= collections.SORTED | collections.DISTINCT
+		case opDistinct:
+			flags &^= collections.SIZED
+			if s.natural {
+				flags |= collections.DISTINCT
+			}
+		case opSort:
+			flags &^= collections.SORTED
+			if s.natural {
+				flags |= collections.SORTED
+			}
+		case opReverse:
+			flags &^= collections.SORTED
+		}
+	}
+}
+
+// pushLimit cuts a SIZED source instead of running Limit, when only Map
+// stands between them. The source can then still be split for parallel runs.
+func (pl *plan[T]) pushLimit() {
+	sp, ok := pl.source.(collections.Spliterator[T])
+	if !ok || !sp.Characteristics().Has(collections.SIZED) {
+		return
+	}
+	if _, ok := pl.source.(collections.Truncater); !ok {
+		return
+	}
+	stages := pl.stages[:0:0]
+	for i, s := range pl.stages {
+		if s.op == opLimit && s.n <= math.MaxInt {
+			if pl.truncate < 0 || int(s.n) < pl.truncate {
+				pl.truncate = int(s.n)
+			}
+			pl.rewrite("pushed %s into the source", s.name)
+			continue
+		}
+		if s.op != opMap {
+			stages = append(stages, pl.stages[i:]...)
+			break
+		}
+		stages = append(stages, s)
+	}
+	pl.stages = stages
+}
+
+// topK replaces Sort directly followed by Limit with a bounded selection,
+// which keeps only k elements per chunk instead of sorting everything.
+func (pl *plan[T]) topK() {
+	var stages []stage[T]
+	for i := 0; i < len(pl.stages); i++ {
+		s := pl.stages[i]
+		if s.op == opSort && i+1 < len(pl.stages) && pl.stages[i+1].op == opLimit &&
+			pl.stages[i+1].n <= math.MaxInt {
+			k := pl.stages[i+1].n
+			stages = append(stages, stage[T]{
+				op:      opTopK,
+				name:    fmt.Sprintf("TopK(%d)", k),
+				n:       k,
+				label:   joinLabels(s.label, pl.stages[i+1].label),
+				less:    s.less,
+				barrier: topK(s.less, k),
+			})
+			pl.rewrite("replaced Sort and %s with TopK(%d)", pl.stages[i+1].name, k)
+			i++
+			continue
+		}
+		stages = append(stages, s)
+	}
+	pl.stages = stages
+}
+
+// fuse merges runs of adjacent Filter and Map into a single stage.
+func (pl *plan[T]) fuse() {
+	var stages []stage[T]
+	for _, s := range pl.stages {
+		last := len(stages) - 1
+		if s.elem == nil || last < 0 || stages[last].elem == nil {
+			stages = append(stages, s)
+			continue
+		}
+		prev := stages[last]
+		op := opMap
+		if prev.op == opFilter || s.op == opFilter {
+			op = opFilter
+		}
+		first, second := prev.elem, s.elem
+		stages[last] = elemStage(op, prev.name+"+"+s.name, func(t T) (T, bool) {
+			if v, ok := first(t); ok {
+				return second(v)
+			}
+			return t, false
+		})
+		stages[last].label = joinLabels(prev.label, s.label)
+		pl.rewrite("fused %s and %s", prev.name, s.name)
+	}
+	pl.stages = stages
+}
+
+func joinLabels(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "+" + b
+}
+
+type ranked[T any] struct {
+	value T
+	pos   int
+}
+
+// boundedHeap keeps the k least elements it has seen, its root is the
+// greatest of them. Ties are broken by position so the selection is stable.
+type boundedHeap[T any] struct {
+	less  function.BiPredicate[T, T]
+	k     int
+	seen  int
+	items []ranked[T]
+}
+
+func (h *boundedHeap[T]) before(a, b ranked[T]) bool {
+	return h.less(a.value, b.value) || !h.less(b.value, a.value) && a.pos < b.pos
+}
+
+func (h *boundedHeap[T]) Len() int           { return len(h.items) }
+func (h *boundedHeap[T]) Less(i, j int) bool { return h.before(h.items[j], h.items[i]) }
+func (h *boundedHeap[T]) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *boundedHeap[T]) Push(x any)         { h.items = append(h.items, x.(ranked[T])) }
+
+func (h *boundedHeap[T]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+func (h *boundedHeap[T]) accept(t T) {
+	item := ranked[T]{value: t, pos: h.seen}
+	h.seen++
+	if len(h.items) < h.k {
+		heap.Push(h, item)
+		return
+	}
+	if h.k > 0 && h.before(item, h.items[0]) {
+		h.items[0] = item
+		heap.Fix(h, 0)
+	}
+}
+
+func (h *boundedHeap[T]) sorted() []T {
+	sort.Slice(h.items, func(i, j int) bool { return h.before(h.items[i], h.items[j]) })
+	res := make([]T, 0, len(h.items))
+	for _, item := range h.items {
+		res = append(res, item.value)
+	}
+	return res
+}
+
+func topK[T any](less function.BiPredicate[T, T], k uint) func(run func(fac func() function.Consumer[T])) []T {
+	return func(run func(fac func() function.Consumer[T])) []T {
+		var heaps []*boundedHeap[T]
+		run(func() function.Consumer[T] {
+			h := &boundedHeap[T]{less: less, k: int(k)}
+			heaps = append(heaps, h)
+			return h.accept
+		})
+		var list []T
+		for _, h := range heaps {
+			list = append(list, h.sorted()...)
+		}
+		// chunks come in encounter order, a stable sort keeps ties in it
+		sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+		if uint(len(list)) > k {
+			list = list[:k]
+		}
+		return list
+	}
+}
@@ -0,0 +1,181 @@
+package stream_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name         string
+		stream       func() stream.Stream[int]
+		wantStages   []string
+		wantRewrites []string
+		want         []int
+	}{
+		{
+			name: "fuse filter and map",
+			stream: func() stream.Stream[int] {
+				return stream.From(1, 2, 3, 4).
+					Filter(func(i int) bool { return i%2 == 0 }).
+					Map(func(i int) int { return i * 10 })
+			},
+			wantStages:   []string{"Filter+Map"},
+			wantRewrites: []string{"fused Filter and Map"},
+			want:         []int{20, 40},
+		},
+		{
+			name: "drop sort on sorted source",
+			stream: func() stream.Stream[int] {
+				return stream.Sort(stream.Range(1, 5).Filter(func(i int) bool { return i != 3 }))
+			},
+			wantStages:   []string{"Filter"},
+			wantRewrites: []string{"dropped Sort, the input is already SORTED"},
+			want:         []int{1, 2, 4, 5},
+		},
+		{
+			name: "keep sort after map",
+			stream: func() stream.Stream[int] {
+				return stream.Sort(stream.Range(1, 3).Map(func(i int) int { return -i }))
+			},
+			wantStages: []string{"Map", "Sort"},
+			want:       []int{-3, -2, -1},
+		},
+		{
+			name: "drop distinct on distinct source",
+			stream: func() stream.Stream[int] {
+				return stream.Distinct(stream.Distinct(stream.Range(1, 3)))
+			},
+			wantRewrites: []string{
+				"dropped Distinct, the input is already DISTINCT",
+				"dropped Distinct, the input is already DISTINCT",
+			},
+			want: []int{1, 2, 3},
+		},
+		{
+			name: "push limit into source",
+			stream: func() stream.Stream[int] {
+				return stream.From(1, 2, 3, 4, 5).Map(func(i int) int { return i * 2 }).Limit(3).Limit(2)
+			},
+			wantStages:   []string{"Map"},
+			wantRewrites: []string{"pushed Limit(3) into the source", "pushed Limit(2) into the source"},
+			want:         []int{2, 4},
+		},
+		{
+			name: "keep limit after filter",
+			stream: func() stream.Stream[int] {
+				return stream.From(1, 2, 3, 4, 5).Filter(func(i int) bool { return i > 1 }).Limit(2)
+			},
+			wantStages: []string{"Filter", "Limit(2)"},
+			want:       []int{2, 3},
+		},
+		{
+			name: "sort and limit to top k",
+			stream: func() stream.Stream[int] {
+				return stream.From(5, 3, 9, 1, 7).Filter(func(i int) bool { return i != 9 }).
+					Sort(func(i, j int) bool { return i > j }).Limit(2)
+			},
+			wantStages:   []string{"Filter", "TopK(2)"},
+			wantRewrites: []string{"replaced Sort and Limit(2) with TopK(2)"},
+			want:         []int{7, 5},
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			plan := v.stream().Explain()
+			var ops []string
+			for _, info := range plan.Stages {
+				ops = append(ops, info.Op)
+			}
+			assert.Equal(t, v.wantStages, ops)
+			assert.Equal(t, v.wantRewrites, plan.Rewrites)
+			assert.Equal(t, v.want, v.stream().ToSlice())
+			assert.Equal(t, v.want, v.stream().Parallel().ToSlice())
+			assert.Equal(t, len(v.want), v.stream().Count())
+		})
+	}
+	t.Run("string", func(t *testing.T) {
+		s := stream.Label(stream.Range(1, 10).Map(func(i int) int { return i + 1 }), "inc").
+			Limit(3).Sort(func(i, j int) bool { return i > j })
+		assert.Equal(t, "fast engine, parallelism 1\n"+
+			"source [SIZED|ORDERED|SORTED|DISTINCT|IMMUTABLE]\n"+
+			"  1. Map \"inc\"\n"+
+			"  2. Sort [stateful barrier]\n"+
+			"rewrites:\n"+
+			"  pushed Limit(3) into the source\n", s.Explain().String())
+	})
+	t.Run("map to", func(t *testing.T) {
+		s := stream.From(3, 1, 2).Filter(func(i int) bool { return i > 1 }).Limit(1).
+			MapToString(func(i int) string { return fmt.Sprint(i) }).
+			Filter(func(s string) bool { return s != "" })
+		plan := s.Explain()
+		assert.Equal(t, []stream.StageInfo{
+			{Op: "Filter", Parallelism: 1},
+			{Op: "Limit(1)", Stateful: true, ShortCircuit: true, Parallelism: 1},
+			{Op: "MapToString", Parallelism: 1},
+			{Op: "Filter", Parallelism: 1},
+		}, plan.Stages)
+		assert.Equal(t, []string{"3"}, s.ToSlice())
+	})
+	t.Run("parallel", func(t *testing.T) {
+		plan := stream.From(3, 1, 2).Parallel().Filter(func(i int) bool { return i > 1 }).Skip(1).Explain()
+		n := stream.GetParallelism()
+		assert.Equal(t, n, plan.Parallelism)
+		assert.Equal(t, []stream.StageInfo{
+			{Op: "Filter", Parallelism: n},
+			{Op: "Skip(1)", Stateful: true, Barrier: true, Parallelism: 1},
+		}, plan.Stages)
+	})
+	t.Run("dot", func(t *testing.T) {
+		s := stream.Label(stream.From(3, 1, 2).Filter(func(i int) bool { return i > 1 }), `big "ones"`).Limit(1)
+		assert.Equal(t, "digraph plan {\n"+
+			"\trankdir=LR;\n"+
+			"\tnode [shape=box];\n"+
+			"\tsource [label=\"fast engine\\nSIZED|ORDERED\", shape=ellipse];\n"+
+			"\ts1 [label=\"Filter\\nbig \\\"ones\\\"\"];\n"+
+			"\tsource -> s1;\n"+
+			"\ts2 [label=\"Limit(1)\", peripheries=2];\n"+
+			"\ts1 -> s2;\n"+
+			"}\n", s.Explain().DOT())
+	})
+	t.Run("other engines", func(t *testing.T) {
+		plan := stream.Builder[int]().Source(1).Simple().Explain()
+		assert.Equal(t, "simple", plan.Engine)
+		assert.Empty(t, plan.Stages)
+	})
+}
+
+func TestTopK(t *testing.T) {
+	type item struct {
+		key, pos int
+	}
+	var list []item
+	for i := 0; i < 100; i++ {
+		list = append(list, item{key: (i * 37) % 10, pos: i})
+	}
+	byKey := func(a, b item) bool { return a.key < b.key }
+	want := []item{{0, 0}, {0, 10}, {0, 20}, {0, 30}, {0, 40}, {0, 50}, {0, 60}, {0, 70}, {0, 80}, {0, 90}, {1, 3}, {1, 13}}
+	for name, s := range engines(list) {
+		t.Run(name, func(t *testing.T) {
+			got := s().Sort(byKey).Limit(12).ToSlice()
+			if name == "fast" || name == "fast-parallel" {
+				// the bounded selection keeps ties in encounter order
+				assert.Equal(t, want, got)
+			}
+			keys := func(list []item) (res []int) {
+				for _, v := range list {
+					res = append(res, v.key)
+				}
+				return
+			}
+			assert.Equal(t, keys(want), keys(got))
+		})
+	}
+	assert.Empty(t, stream.From(list...).Sort(byKey).Limit(0).ToSlice())
+	assert.Empty(t, stream.From[item]().Sort(byKey).Limit(3).ToSlice())
+	// a limit past the end sorts everything
+	assert.Equal(t, 100, len(stream.From(list...).Sort(byKey).Limit(1000).ToSlice()))
+}
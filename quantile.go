@@ -0,0 +1,202 @@
+package stream
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/function"
+	"github.com/go-park/stream/support/optional"
+)
+
+// DefaultSketchSize is the accuracy parameter used by Quantile and Quantiles,
+// the rank error of a sketch is roughly 1.7/k.
+const DefaultSketchSize = 200
+
+// QuantileSketch is a KLL sketch answering approximate quantile queries in
+// O(k) memory. Until its first compaction it holds every element and the
+// answers are exact.
+type QuantileSketch[T Number] struct {
+	k      int
+	count  int
+	size   int
+	coin   bool
+	levels [][]T
+}
+
+func NewQuantileSketch[T Number](k int) *QuantileSketch[T] {
+	if k < 8 {
+		k = 8
+	}
+	return &QuantileSketch[T]{k: k, levels: make([][]T, 1)}
+}
+
+// capacity shrinks geometrically from the top level down.
+func (q *QuantileSketch[T]) capacity(h int) int {
+	depth := len(q.levels) - h - 1
+	c := int(math.Ceil(float64(q.k)*math.Pow(2.0/3, float64(depth)))) + 1
+	if c < 2 {
+		return 2
+	}
+	return c
+}
+
+func (q *QuantileSketch[T]) maxSize() int {
+	size := 0
+	for h := range q.levels {
+		size += q.capacity(h)
+	}
+	return size
+}
+
+func (q *QuantileSketch[T]) compact(h int) {
+	if h+1 == len(q.levels) {
+		q.levels = append(q.levels, nil)
+	}
+	level := q.levels[h]
+	sort.Slice(level, func(i, j int) bool { return level[i] < level[j] })
+	var rest []T
+	if len(level)%2 == 1 {
+		rest = []T{level[len(level)-1]}
+		level = level[:len(level)-1]
+	}
+	offset := 0
+	if q.coin {
+		offset = 1
+	}
+	q.coin = !q.coin
+	for i := offset; i < len(level); i += 2 {
+		q.levels[h+1] = append(q.levels[h+1], level[i])
+	}
+	q.levels[h] = rest
+	q.size -= len(level) / 2
+}
+
+func (q *QuantileSketch[T]) compress() {
+	for q.size >= q.maxSize() {
+		for h := range q.levels {
+			if len(q.levels[h]) >= q.capacity(h) {
+				q.compact(h)
+				break
+			}
+		}
+	}
+}
+
+func (q *QuantileSketch[T]) Accept(t T) {
+	q.levels[0] = append(q.levels[0], t)
+	q.count++
+	q.size++
+	if q.size >= q.maxSize() {
+		q.compress()
+	}
+}
+
+// Merge adds every element summarised by other to q.
+func (q *QuantileSketch[T]) Merge(other *QuantileSketch[T]) {
+	helper.RequireNonNil(other)
+	for len(q.levels) < len(other.levels) {
+		q.levels = append(q.levels, nil)
+	}
+	for h, level := range other.levels {
+		q.levels[h] = append(q.levels[h], level...)
+		q.size += len(level)
+	}
+	q.count += other.count
+	q.compress()
+}
+
+func (q *QuantileSketch[T]) Count() int {
+	return q.count
+}
+
+// Exact reports whether the sketch still holds every element it has seen.
+func (q *QuantileSketch[T]) Exact() bool {
+	return len(q.levels) == 1
+}
+
+// Quantile returns the smallest element whose rank reaches phi*Count, phi in [0, 1].
+func (q *QuantileSketch[T]) Quantile(phi float64) optional.Value[T] {
+	if q.count == 0 {
+		return optional.EmptyVal[T]()
+	}
+	return optional.ValOf(q.Quantiles(phi)[0])
+}
+
+func (q *QuantileSketch[T]) Quantiles(phis ...float64) []T {
+	if q.count == 0 {
+		return nil
+	}
+	res := make([]T, 0, len(phis))
+	if q.Exact() {
+		sorted := Sort(From(q.levels[0]...)).ToSlice()
+		for _, phi := range phis {
+			res = append(res, sorted[rank(phi, len(sorted))-1])
+		}
+		return res
+	}
+	type weighted struct {
+		value  T
+		weight int
+	}
+	var items []weighted
+	for h, level := range q.levels {
+		for _, v := range level {
+			items = append(items, weighted{value: v, weight: 1 << h})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].value < items[j].value })
+	total := 0
+	for _, item := range items {
+		total += item.weight
+	}
+	for _, phi := range phis {
+		target := rank(phi, total)
+		cum := 0
+		for _, item := range items {
+			cum += item.weight
+			if cum >= target {
+				res = append(res, item.value)
+				break
+			}
+		}
+	}
+	return res
+}
+
+func rank(phi float64, n int) int {
+	r := int(math.Ceil(phi * float64(n)))
+	if r < 1 {
+		return 1
+	}
+	if r > n {
+		return n
+	}
+	return r
+}
+
+// QuantileSketchOf summarises s into a sketch of accuracy k, parallel chunks
+// are sketched on their own and merged.
+func QuantileSketchOf[T Number](s Stream[T], k int) *QuantileSketch[T] {
+	helper.RequireCanButNonNil(s)
+	var list []*QuantileSketch[T]
+	forEachChunk(s, func() function.Consumer[T] {
+		sketch := NewQuantileSketch[T](k)
+		list = append(list, sketch)
+		return sketch.Accept
+	})
+	res := NewQuantileSketch[T](k)
+	for _, sketch := range list {
+		res.Merge(sketch)
+	}
+	return res
+}
+
+func Quantile[T Number](s Stream[T], phi float64) optional.Value[T] {
+	return QuantileSketchOf(s, DefaultSketchSize).Quantile(phi)
+}
+
+// Quantiles returns one element per phi, nil if s is empty.
+func Quantiles[T Number](s Stream[T], phis ...float64) []T {
+	return QuantileSketchOf(s, DefaultSketchSize).Quantiles(phis...)
+}
@@ -0,0 +1,55 @@
+package stream_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantile(t *testing.T) {
+	t.Run("exact", func(t *testing.T) {
+		list := []int{9, 1, 8, 2, 7, 3, 6, 4, 5, 10}
+		assert.Equal(t, []int{1, 5, 10, 10}, stream.Quantiles(stream.From(list...), 0, 0.5, 0.95, 1))
+		assert.Equal(t, 9, stream.Quantile(stream.From(list...), 0.9).Get())
+		assert.Equal(t, true, stream.Quantile(stream.From[int](), 0.5).IsEmpty())
+	})
+
+	n := 100000
+	list := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		// a permutation of 0..n-1
+		list = append(list, float64(i*7919%n))
+	}
+
+	t.Run("approximate", func(t *testing.T) {
+		sketch := stream.QuantileSketchOf(stream.From(list...), 200)
+		assert.Equal(t, n, sketch.Count())
+		assert.Equal(t, false, sketch.Exact())
+		for _, phi := range []float64{0.5, 0.95, 0.99} {
+			got := sketch.Quantile(phi).Get()
+			assert.LessOrEqual(t, math.Abs(got-phi*float64(n)), 0.02*float64(n), phi)
+		}
+	})
+
+	t.Run("edges", func(t *testing.T) {
+		// phi is clamped to [0, 1]
+		assert.Equal(t, []int{1, 3}, stream.Quantiles(stream.From(2, 3, 1), -1, 2))
+		assert.Nil(t, stream.Quantiles(stream.From[int](), 0.5))
+		// k is raised to the smallest accuracy supported
+		sketch := stream.QuantileSketchOf(stream.Range(1, 100), 0)
+		assert.Equal(t, 100, sketch.Count())
+		assert.InDelta(t, 50, sketch.Quantile(0.5).Get(), 20)
+		sketch.Merge(stream.NewQuantileSketch[int](8))
+		assert.Equal(t, 100, sketch.Count())
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		left := stream.QuantileSketchOf(stream.From(list[:n/2]...), 200)
+		right := stream.QuantileSketchOf(stream.From(list[n/2:]...), 200)
+		left.Merge(right)
+		assert.Equal(t, n, left.Count())
+		assert.LessOrEqual(t, math.Abs(left.Quantile(0.5).Get()-float64(n)/2), 0.02*float64(n))
+	})
+}
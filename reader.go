@@ -0,0 +1,184 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-park/stream/support/function"
+)
+
+// scanIterator yields the tokens of a bufio.Scanner. It closes closer once
+// the input is exhausted, fails or the stream is closed.
+type scanIterator[T any] struct {
+	scanner *bufio.Scanner
+	token   func(*bufio.Scanner) T
+	closer  io.Closer
+	ready   bool
+	done    bool
+	err     error
+	closed  int32
+	once    sync.Once
+}
+
+func (it *scanIterator[T]) HasNext() bool {
+	if it.ready {
+		return true
+	}
+	if it.done || atomic.LoadInt32(&it.closed) == 1 {
+		return false
+	}
+	if it.scanner.Scan() {
+		it.ready = true
+		return true
+	}
+	it.done = true
+	// a read failing because the stream was closed is no error
+	if atomic.LoadInt32(&it.closed) == 0 {
+		it.err = it.scanner.Err()
+	}
+	it.Close()
+	return false
+}
+
+func (it *scanIterator[T]) Next() T {
+	var v T
+	if it.HasNext() {
+		v, it.ready = it.token(it.scanner), false
+	}
+	return v
+}
+
+func (it *scanIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for it.HasNext() {
+		fn(it.Next())
+	}
+}
+
+func (it *scanIterator[T]) Err() error {
+	return it.err
+}
+
+func (it *scanIterator[T]) Close() error {
+	var err error
+	it.once.Do(func() {
+		atomic.StoreInt32(&it.closed, 1)
+		if it.closer != nil {
+			err = it.closer.Close()
+		}
+	})
+	return err
+}
+
+func scanText(scanner *bufio.Scanner) string {
+	return scanner.Text()
+}
+
+func scan[T any](scanner *bufio.Scanner, token func(*bufio.Scanner) T, closer io.Closer) Stream[T] {
+	return Builder[T]().iterator(&scanIterator[T]{scanner: scanner, token: token, closer: closer}).Build()
+}
+
+// FromScanner yields the tokens of scanner as strings, a failed scan ends
+// the stream and is reported by Err.
+func FromScanner(scanner *bufio.Scanner) Stream[string] {
+	return scan(scanner, scanText, nil)
+}
+
+// FromScannerBytes yields the tokens of scanner without copying them, a
+// token is only valid until the next one is read. Copy the tokens before
+// any stage that holds on to them, such as Sort, and before going parallel.
+func FromScannerBytes(scanner *bufio.Scanner) Stream[[]byte] {
+	return scan(scanner, (*bufio.Scanner).Bytes, nil)
+}
+
+// FromLines yields the lines of r without their end of line marker.
+// Lines longer than bufio.MaxScanTokenSize fail the stream, use
+// FromScanner with a larger buffer to read them.
+func FromLines(r io.Reader) Stream[string] {
+	return FromScanner(bufio.NewScanner(r))
+}
+
+// FromDelimited yields the parts of r separated by sep, which are dropped.
+func FromDelimited(r io.Reader, sep string) Stream[string] {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(splitOn([]byte(sep)))
+	return FromScanner(scanner)
+}
+
+func splitOn(sep []byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if len(sep) > 0 {
+			if i := bytes.Index(data, sep); i >= 0 {
+				return i + len(sep), data[:i], nil
+			}
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// readCloser closes both a decompressor and the file underneath it.
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (rc readCloser) Close() error {
+	var first error
+	for _, c := range rc.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// openFile opens path, decompressing it when it starts with the gzip magic
+// number whatever its name.
+func openFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := gunzip(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	rc.closers = append(rc.closers, f)
+	return rc, nil
+}
+
+// gunzip decompresses r when it starts with the gzip magic number and
+// reads it as is otherwise.
+func gunzip(r io.Reader) (readCloser, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return readCloser{Reader: br}, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return readCloser{}, err
+	}
+	return readCloser{Reader: zr, closers: []io.Closer{zr}}, nil
+}
+
+// FromFile yields the lines of the file at path, gzip compressed or not.
+// The file is closed once the stream is exhausted, fails or is closed.
+func FromFile(path string) (Stream[string], error) {
+	rc, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return scan(bufio.NewScanner(rc), scanText, rc), nil
+}
@@ -0,0 +1,97 @@
+package stream_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingReader yields data then fails with err.
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestFromLines(t *testing.T) {
+	t.Run("lines", func(t *testing.T) {
+		s := stream.FromLines(strings.NewReader("a\r\nbb\n\nccc"))
+		assert.Equal(t, []string{"a", "bb", "", "ccc"}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+	t.Run("delimited", func(t *testing.T) {
+		s := stream.FromDelimited(strings.NewReader("a, b, , c"), ", ")
+		assert.Equal(t, []string{"a", "b", "", "c"}, s.ToSlice())
+	})
+	t.Run("scanner bytes", func(t *testing.T) {
+		scanner := bufio.NewScanner(strings.NewReader("one two three"))
+		scanner.Split(bufio.ScanWords)
+		lens := stream.FromScannerBytes(scanner).MapToInt(func(b []byte) int { return len(b) }).ToSlice()
+		assert.Equal(t, []int{3, 3, 5}, lens)
+	})
+	t.Run("read error", func(t *testing.T) {
+		boom := errors.New("boom")
+		s := stream.FromLines(&failingReader{data: "a\nb\n", err: boom}).
+			MapToInt(func(s string) int { return len(s) })
+		assert.Equal(t, []int{1, 1}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), boom)
+	})
+	t.Run("line too long", func(t *testing.T) {
+		s := stream.FromLines(strings.NewReader(strings.Repeat("x", bufio.MaxScanTokenSize+1)))
+		assert.Empty(t, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), bufio.ErrTooLong)
+	})
+}
+
+func TestFromFile(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.log")
+	assert.NoError(t, os.WriteFile(plain, []byte("1\n2\n3\n"), 0o600))
+	compressed := filepath.Join(dir, "compressed.log")
+	f, err := os.Create(compressed)
+	assert.NoError(t, err)
+	zw := gzip.NewWriter(f)
+	_, err = io.WriteString(zw, "4\n5\n")
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, f.Close())
+
+	t.Run("plain", func(t *testing.T) {
+		s, err := stream.FromFile(plain)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1", "2", "3"}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+	t.Run("gzip", func(t *testing.T) {
+		s, err := stream.FromFile(compressed)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"4", "5"}, s.Parallel().ToSlice())
+	})
+	t.Run("closed", func(t *testing.T) {
+		s, err := stream.FromFile(plain)
+		assert.NoError(t, err)
+		s.Close()
+		assert.Empty(t, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+	t.Run("missing", func(t *testing.T) {
+		_, err := stream.FromFile(filepath.Join(dir, "missing.log"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,121 @@
+package stream
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/function"
+)
+
+func orRandom(rnd *rand.Rand) *rand.Rand {
+	if rnd == nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rnd
+}
+
+// reservoir is a uniform sample of at most n of the elements seen so far.
+type reservoir[T any] struct {
+	n     int
+	seen  int
+	items []T
+	rnd   *rand.Rand
+}
+
+func (r *reservoir[T]) accept(t T) {
+	r.seen++
+	if len(r.items) < r.n {
+		r.items = append(r.items, t)
+		return
+	}
+	if j := r.rnd.Intn(r.seen); j < r.n {
+		r.items[j] = t
+	}
+}
+
+// merge draws from both reservoirs in proportion to the elements they have
+// seen, the result is a uniform sample of their union.
+func (r *reservoir[T]) merge(other *reservoir[T]) {
+	remain, otherRemain := r.seen, other.seen
+	items, otherItems := r.items, other.items
+	merged := make([]T, 0, r.n)
+	take := func(list []T) ([]T, T) {
+		i := r.rnd.Intn(len(list))
+		v := list[i]
+		list[i] = list[len(list)-1]
+		return list[:len(list)-1], v
+	}
+	for len(merged) < r.n && len(items)+len(otherItems) > 0 {
+		var v T
+		if r.rnd.Intn(remain+otherRemain) < remain {
+			items, v = take(items)
+			remain--
+		} else {
+			otherItems, v = take(otherItems)
+			otherRemain--
+		}
+		merged = append(merged, v)
+	}
+	r.seen += other.seen
+	r.items = merged
+}
+
+// Sample keeps n elements of s chosen uniformly at random by reservoir sampling.
+// Parallel chunks fill their own reservoir, which are merged at the end.
+// A nil rnd is seeded from the current time.
+func Sample[T any](s Stream[T], n int, rnd *rand.Rand) Stream[T] {
+	helper.RequireCanButNonNil(s)
+	if n < 0 {
+		panic("sample size must not be negative")
+	}
+	return fromEach(func(down function.Consumer[T], more func() bool) {
+		rnd := orRandom(rnd)
+		var list []*reservoir[T]
+		forEachChunk(s, func() function.Consumer[T] {
+			// rand.Rand is not safe for concurrent use, each chunk gets its own
+			r := &reservoir[T]{n: n, rnd: rand.New(rand.NewSource(rnd.Int63()))}
+			list = append(list, r)
+			return r.accept
+		})
+		res := &reservoir[T]{n: n, rnd: rnd}
+		for _, r := range list {
+			res.merge(r)
+		}
+		for _, v := range res.items {
+			if !more() {
+				return
+			}
+			down(v)
+		}
+	}, s)
+}
+
+// SampleFraction keeps each element of s independently with probability p.
+func SampleFraction[T any](s Stream[T], p float64, rnd *rand.Rand) Stream[T] {
+	helper.RequireCanButNonNil(s)
+	return fromEach(func(down function.Consumer[T], more func() bool) {
+		rnd := orRandom(rnd)
+		forEachUpstream(s, more, func(t T) {
+			if rnd.Float64() < p {
+				down(t)
+			}
+		})
+	}, s)
+}
+
+// Shuffle emits the elements of s in a uniformly random order.
+func Shuffle[T any](s Stream[T], rnd *rand.Rand) Stream[T] {
+	helper.RequireCanButNonNil(s)
+	return fromEach(func(down function.Consumer[T], more func() bool) {
+		rnd := orRandom(rnd)
+		list := s.Sequential().ToSlice()
+		rnd.Shuffle(len(list), func(i, j int) { list[i], list[j] = list[j], list[i] })
+		for _, v := range list {
+			if !more() {
+				return
+			}
+			down(v)
+		}
+	}, s)
+}
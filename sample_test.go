@@ -0,0 +1,80 @@
+package stream_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSample(t *testing.T) {
+	var list []int
+	for i := 0; i < 1000; i++ {
+		list = append(list, i)
+	}
+
+	t.Run("sample", func(t *testing.T) {
+		first := stream.Sample(stream.From(list...), 10, rand.New(rand.NewSource(42))).ToSlice()
+		second := stream.Sample(stream.From(list...), 10, rand.New(rand.NewSource(42))).ToSlice()
+		assert.Equal(t, 10, len(first))
+		assert.Equal(t, first, second)
+		assert.Equal(t, 10, stream.Distinct(stream.From(first...)).Count())
+		// the reservoirs of the parallel chunks are merged into one sample
+		merged := stream.Sample(stream.From(list...).Parallel(), 10, nil).ToSlice()
+		assert.Equal(t, 10, stream.Distinct(stream.From(merged...)).Count())
+		assert.Empty(t, stream.Sample(stream.From[int](), 5, nil).ToSlice())
+		assert.Equal(t, []int{1, 2}, stream.Sort(stream.Sample(stream.From(2, 1), 5, nil)).ToSlice())
+		assert.Equal(t, 0, stream.Sample(stream.From(2, 1), 0, nil).Count())
+		assert.Panics(t, func() { stream.Sample(stream.From(1), -1, nil) })
+	})
+
+	t.Run("sample-uniform", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(1))
+		hits := make(map[int]int)
+		for i := 0; i < 4000; i++ {
+			for _, v := range stream.Sample(stream.From(0, 1, 2, 3), 1, rnd).ToSlice() {
+				hits[v]++
+			}
+		}
+		for i := 0; i < 4; i++ {
+			assert.InDelta(t, 1000, hits[i], 150)
+		}
+	})
+
+	t.Run("sampleFraction", func(t *testing.T) {
+		s := stream.SampleFraction(stream.From(list...), 0.2, rand.New(rand.NewSource(7)))
+		assert.InDelta(t, 200, s.Count(), 60)
+		assert.Equal(t, 0, stream.SampleFraction(stream.From(list...), 0, nil).Count())
+		assert.Equal(t, 0, stream.SampleFraction(stream.From(list...), -1, nil).Count())
+		assert.Equal(t, list, stream.SampleFraction(stream.From(list...), 1, nil).ToSlice())
+		assert.Empty(t, stream.SampleFraction(stream.From[int](), 0.5, nil).ToSlice())
+	})
+
+	t.Run("shuffle", func(t *testing.T) {
+		first := stream.Shuffle(stream.From(list...), rand.New(rand.NewSource(3))).ToSlice()
+		second := stream.Shuffle(stream.From(list...), rand.New(rand.NewSource(3))).ToSlice()
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, list, first)
+		sorted := append([]int(nil), first...)
+		sort.Ints(sorted)
+		assert.Equal(t, list, sorted)
+		assert.Empty(t, stream.Shuffle(stream.From[int](), nil).ToSlice())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		lines := func() stream.Stream[string] {
+			return stream.FromLines(&failingReader{data: "a\nb\n", err: errTransient})
+		}
+		s := stream.Sample(lines(), 5, nil)
+		assert.ElementsMatch(t, []string{"a", "b"}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errTransient)
+		s = stream.SampleFraction(lines(), 1, nil)
+		assert.Equal(t, []string{"a", "b"}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errTransient)
+		s = stream.Shuffle(lines(), nil)
+		assert.ElementsMatch(t, []string{"a", "b"}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errTransient)
+	})
+}
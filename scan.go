@@ -0,0 +1,57 @@
+package stream
+
+import (
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/function"
+	"golang.org/x/exp/constraints"
+)
+
+// Scan emits the accumulator state after each element of s, starting from identity.
+// The identity itself is not emitted, so the output has as many elements as s.
+func Scan[T, R any](s Stream[T], identity R, acc function.BiFunc[R, T, R]) Stream[R] {
+	helper.RequireCanButNonNil(s)
+	helper.RequireCanButNonNil(acc)
+	return fromEach(func(down function.Consumer[R], more func() bool) {
+		state := identity
+		forEachUpstream(s, more, func(t T) {
+			state = acc.Apply(state, t)
+			down(state)
+		})
+	}, s)
+}
+
+// ScanLeft is Scan emitting identity first, the output has one element more than s.
+func ScanLeft[T, R any](s Stream[T], identity R, acc function.BiFunc[R, T, R]) Stream[R] {
+	helper.RequireCanButNonNil(s)
+	helper.RequireCanButNonNil(acc)
+	return fromEach(func(down function.Consumer[R], more func() bool) {
+		state := identity
+		down(state)
+		forEachUpstream(s, more, func(t T) {
+			state = acc.Apply(state, t)
+			down(state)
+		})
+	}, s)
+}
+
+func RunningSum[T Number](s Stream[T]) Stream[T] {
+	return Scan(s, 0, func(sum, t T) T { return sum + t })
+}
+
+func RunningMax[T constraints.Ordered](s Stream[T]) Stream[T] {
+	helper.RequireCanButNonNil(s)
+	return fromEach(func(down function.Consumer[T], more func() bool) {
+		var max T
+		first := true
+		forEachUpstream(s, more, func(t T) {
+			if first || t > max {
+				max, first = t, false
+			}
+			down(max)
+		})
+	}, s)
+}
+
+func CumulativeCount[T any](s Stream[T]) Stream[int] {
+	return Scan(s, 0, func(i int, _ T) int { return i + 1 })
+}
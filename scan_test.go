@@ -0,0 +1,79 @@
+package stream_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScan(t *testing.T) {
+	list := []int{3, 1, 4, 1, 5, 9, 2, 6}
+
+	t.Run("scan", func(t *testing.T) {
+		s := stream.Scan(stream.From(list...), "", func(r string, i int) string {
+			return r + string(rune('0'+i))
+		})
+		assert.Equal(t, []string{"3", "31", "314", "3141", "31415", "314159", "3141592", "31415926"}, s.ToSlice())
+	})
+
+	t.Run("scanLeft", func(t *testing.T) {
+		s := stream.ScanLeft(stream.From(1, 2, 3), 10, func(r, i int) int { return r + i })
+		assert.Equal(t, []int{10, 11, 13, 16}, s.ToSlice())
+	})
+
+	t.Run("scanLeft-empty", func(t *testing.T) {
+		s := stream.ScanLeft(stream.From[int](), 10, func(r, i int) int { return r + i })
+		assert.Equal(t, []int{10}, s.ToSlice())
+	})
+
+	t.Run("runningSum", func(t *testing.T) {
+		assert.Equal(t, []int{3, 4, 8, 9, 14, 23, 25, 31}, stream.RunningSum(stream.From(list...)).ToSlice())
+		assert.Equal(t, []float64{0.5, 1.75}, stream.RunningSum(stream.From(0.5, 1.25)).ToSlice())
+	})
+
+	t.Run("runningMax", func(t *testing.T) {
+		assert.Equal(t, []int{3, 3, 4, 4, 5, 9, 9, 9}, stream.RunningMax(stream.From(list...)).ToSlice())
+		assert.Equal(t, []int{-3, -1}, stream.RunningMax(stream.From(-3, -1)).ToSlice())
+	})
+
+	t.Run("cumulativeCount", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3}, stream.CumulativeCount(stream.From("a", "b", "c")).ToSlice())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, stream.RunningSum(stream.From[int]()).ToSlice())
+		assert.Empty(t, stream.RunningMax(stream.From[int]()).ToSlice())
+		assert.Empty(t, stream.CumulativeCount(stream.From[string]()).ToSlice())
+	})
+
+	t.Run("scanLeft-limit", func(t *testing.T) {
+		// the identity alone is taken, the source is not read
+		read := 0
+		s := stream.From(1, 2, 3).Map(func(i int) int {
+			read++
+			return i
+		})
+		assert.Equal(t, []int{10}, stream.ScanLeft(s, 10, func(r, i int) int { return r + i }).Limit(1).ToSlice())
+		assert.Equal(t, 0, read)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		s := stream.CumulativeCount(stream.Range(1, math.MaxInt))
+		var last int
+		s.ForEach(func(i int) {
+			last = i
+			if i == 5 {
+				s.Close()
+			}
+		})
+		assert.Equal(t, 5, last)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s := stream.CumulativeCount(stream.FromLines(&failingReader{data: "a\nb\n", err: errTransient}))
+		assert.Equal(t, []int{1, 2}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errTransient)
+	})
+}
@@ -0,0 +1,124 @@
+//go:build go1.23
+
+package stream
+
+import (
+	"iter"
+
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+)
+
+// seqIterator ranges over seq when drained, so that a short-circuiting
+// stage ends the loop. It only pulls through iter.Pull when stepped.
+type seqIterator[T any] struct {
+	seq  iter.Seq[T]
+	rest collections.Iterator[T]
+}
+
+func (it *seqIterator[T]) pulled() collections.Iterator[T] {
+	if it.rest == nil {
+		it.rest = collections.IterableSeq(it.seq)
+	}
+	return it.rest
+}
+
+func (it *seqIterator[T]) HasNext() bool {
+	return it.pulled().HasNext()
+}
+
+func (it *seqIterator[T]) Next() T {
+	return it.pulled().Next()
+}
+
+func (it *seqIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	it.forEachWhile(func(t T) bool {
+		fn(t)
+		return true
+	})
+}
+
+func (it *seqIterator[T]) forEachWhile(fn func(T) bool) {
+	if it.rest != nil {
+		for it.rest.HasNext() {
+			if !fn(it.rest.Next()) {
+				return
+			}
+		}
+		return
+	}
+	it.rest = collections.IterableSlice[T]()
+	for v := range it.seq {
+		if !fn(v) {
+			return
+		}
+	}
+}
+
+// FromSeq streams the values of seq, which is stopped as soon as the
+// stream needs no more of them.
+func FromSeq[T any](seq iter.Seq[T]) Stream[T] {
+	return Builder[T]().iterator(&seqIterator[T]{seq: seq}).Build()
+}
+
+// FromSeq2 streams the pairs of seq as entries, like the ones of FromMap.
+func FromSeq2[K comparable, V any](seq iter.Seq2[K, V]) Stream[collections.Entry[K, V]] {
+	return FromSeq(seq2(seq, collections.EntryOf[K, V]))
+}
+
+// FromSeq2Pairs streams the pairs of seq, whose keys need not be comparable.
+func FromSeq2Pairs[K, V any](seq iter.Seq2[K, V]) Stream[collections.Pair[K, V]] {
+	return FromSeq(seq2(seq, collections.PairOf[K, V]))
+}
+
+func seq2[K, V, R any](seq iter.Seq2[K, V], of func(K, V) R) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		for k, v := range seq {
+			if !yield(of(k, v)) {
+				return
+			}
+		}
+	}
+}
+
+// All ranges over the elements of s, breaking out of the loop stops s.
+func All[T any](s Stream[T]) iter.Seq[T] {
+	return s.(interface{ All() iter.Seq[T] }).All()
+}
+
+// All yields the elements in encounter order, a break closes the stream so
+// that its source stops. A parallel stream is computed in full first.
+func (p *FastPipline[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if p.parallel {
+			yieldAll(p.ToSlice(), yield)
+			return
+		}
+		stopped := false
+		p.ForEach(func(t T) {
+			if !stopped && !yield(t) {
+				stopped = true
+				p.Close()
+			}
+		})
+	}
+}
+
+// All yields the elements once the whole stream is computed.
+func (p SimplePipline[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		yieldAll(p.ToSlice(), yield)
+	}
+}
+
+func (p ParallelPipline[T]) All() iter.Seq[T] {
+	return p.sp.All()
+}
+
+func yieldAll[T any](list []T, yield func(T) bool) {
+	for _, v := range list {
+		if !yield(v) {
+			return
+		}
+	}
+}
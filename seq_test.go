@@ -0,0 +1,99 @@
+//go:build go1.23
+
+package stream_test
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/go-park/stream/support/collections"
+	"github.com/stretchr/testify/assert"
+)
+
+// naturals yields 0, 1, 2... and counts how many were pulled.
+func naturals(pulled *int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			*pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestFromSeq(t *testing.T) {
+	t.Run("values", func(t *testing.T) {
+		s := stream.FromSeq(slices.Values([]int{3, 1, 2}))
+		assert.Equal(t, []int{1, 2, 3}, stream.Sort(s).ToSlice())
+	})
+	t.Run("limit stops the source", func(t *testing.T) {
+		pulled := 0
+		list := stream.FromSeq(naturals(&pulled)).
+			Filter(func(i int) bool { return i%2 == 1 }).
+			Limit(3).
+			ToSlice()
+		assert.Equal(t, []int{1, 3, 5}, list)
+		assert.Equal(t, 6, pulled)
+	})
+	t.Run("seq2", func(t *testing.T) {
+		s := stream.FromSeq2(maps.All(map[string]int{"a": 1, "b": 2}))
+		entries := stream.Sort(s.MapToString(func(e collections.Entry[string, int]) string {
+			return e.Key()
+		})).ToSlice()
+		assert.Equal(t, []string{"a", "b"}, entries)
+		pairs := stream.FromSeq2Pairs(slices.All([]string{"x", "y"})).ToSlice()
+		assert.Equal(t, []collections.Pair[int, string]{
+			collections.PairOf(0, "x"),
+			collections.PairOf(1, "y"),
+		}, pairs)
+	})
+}
+
+func TestAll(t *testing.T) {
+	for name, s := range engines([]int{1, 2, 3, 4}) {
+		t.Run(name, func(t *testing.T) {
+			var list []int
+			for v := range stream.All(s().Map(func(i int) int { return i * 10 })) {
+				list = append(list, v)
+			}
+			if name == "simple-parallel" {
+				// the simple engine maps its chunks concurrently, out of order
+				assert.ElementsMatch(t, []int{10, 20, 30, 40}, list)
+				return
+			}
+			assert.Equal(t, []int{10, 20, 30, 40}, list)
+		})
+	}
+	t.Run("empty", func(t *testing.T) {
+		for range stream.All(stream.From[int]()) {
+			t.Fatal("no element expected")
+		}
+	})
+	t.Run("break stops upstream", func(t *testing.T) {
+		pulled := 0
+		var list []int
+		for v := range stream.All(stream.FromSeq(naturals(&pulled)).Map(func(i int) int { return i * i })) {
+			if v > 10 {
+				break
+			}
+			list = append(list, v)
+		}
+		assert.Equal(t, []int{0, 1, 4, 9}, list)
+		assert.Equal(t, 5, pulled)
+	})
+	t.Run("break stops the upstream of a stage", func(t *testing.T) {
+		pulled := 0
+		var list []int
+		for v := range stream.All(stream.RunningSum(stream.FromSeq(naturals(&pulled)))) {
+			if v > 5 {
+				break
+			}
+			list = append(list, v)
+		}
+		assert.Equal(t, []int{0, 1, 3}, list)
+		assert.Equal(t, 4, pulled)
+	})
+}
@@ -13,14 +13,28 @@ import (
 
 type SimplePipline[T any] struct {
 	upstream chan T
+	ctx      context.Context
 	cancel   context.CancelFunc
 	parallel bool
+	// err reports the error of the source, it does not depend on T
+	err func() error
 }
 
 func (p SimplePipline[T]) Close() {
 	p.cancel()
 }
 
+func (p SimplePipline[T]) Err() error {
+	if p.err == nil {
+		return nil
+	}
+	return p.err()
+}
+
+func (p SimplePipline[T]) done() <-chan struct{} {
+	return p.ctx.Done()
+}
+
 func (p SimplePipline[T]) Parallel() Stream[T] {
 	p.parallel = true
 	return p
@@ -31,6 +45,15 @@ func (p SimplePipline[T]) Sequential() Stream[T] {
 	return p
 }
 
+// Explain only describes the engine, channel stages are not planned.
+func (p SimplePipline[T]) Explain() Plan {
+	pl := Plan{Engine: "simple", Parallelism: 1}
+	if p.parallel {
+		pl.Parallelism = GetParallelism()
+	}
+	return pl
+}
+
 func (p SimplePipline[T]) Count() int {
 	acc := func(_ T, i int) int {
 		return i + 1
@@ -281,18 +304,33 @@ func (p SimplePipline[T]) Map(mapper function.Func[T, T]) Stream[T] {
 
 func (p SimplePipline[T]) Reduce(acc function.BiFunc[T, T, T]) optional.Value[T] {
 	helper.RequireCanButNonNil(acc)
-	reduce := func(in chan T, acc function.BiFunc[T, T, T], _ T) optional.Value[T] {
-		val := optional.EmptyVal[T]()
-		for v := range in {
-			val.IfNotEmptyOrElse(
-				func(t T) { val = optional.ValOf(acc.Apply(v, t)) },
-				func() { val = optional.ValOf(v) })
+	return reduceChunks[T](p, acc)
+}
+
+func (p SimplePipline[T]) forEachChunk(fac func() function.Consumer[T]) {
+	if !p.parallel {
+		op := fac()
+		for v := range p.upstream {
+			op(v)
 		}
-		return val
+		return
+	}
+	var list []T
+	var wg sync.WaitGroup
+	for v := range p.upstream {
+		list = append(list, v)
+	}
+	for _, chunk := range split(list, GetParallelism()) {
+		wg.Add(1)
+		op := fac()
+		routine.RunArg(chunk, func(chunk []T) {
+			defer wg.Done()
+			for _, v := range chunk {
+				op(v)
+			}
+		})
 	}
-	var identify T
-	ch := aggregator(reduce, acc, identify, p.chunk())
-	return reduce(ch, acc, identify)
+	wg.Wait()
 }
 
 func (p SimplePipline[T]) MapToAny(mapper function.Func[T, any]) Stream[any] {
@@ -311,8 +349,10 @@ func (p SimplePipline[T]) MapToAny(mapper function.Func[T, any]) Stream[any] {
 		})
 	return SimplePipline[any]{
 		upstream: target,
+		ctx:      p.ctx,
 		cancel:   p.cancel,
 		parallel: p.parallel,
+		err:      p.err,
 	}
 }
 
@@ -332,8 +372,10 @@ func (p SimplePipline[T]) MapToString(mapper function.Func[T, string]) Stream[st
 		})
 	return SimplePipline[string]{
 		upstream: target,
+		ctx:      p.ctx,
 		cancel:   p.cancel,
 		parallel: p.parallel,
+		err:      p.err,
 	}
 }
 
@@ -353,8 +395,10 @@ func (p SimplePipline[T]) MapToInt(mapper function.Func[T, int]) Stream[int] {
 		})
 	return SimplePipline[int]{
 		upstream: target,
+		ctx:      p.ctx,
 		cancel:   p.cancel,
 		parallel: p.parallel,
+		err:      p.err,
 	}
 }
 
@@ -374,8 +418,10 @@ func (p SimplePipline[T]) MapToFloat(mapper function.Func[T, float64]) Stream[fl
 		})
 	return SimplePipline[float64]{
 		upstream: target,
+		ctx:      p.ctx,
 		cancel:   p.cancel,
 		parallel: p.parallel,
+		err:      p.err,
 	}
 }
 
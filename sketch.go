@@ -0,0 +1,362 @@
+package stream
+
+import (
+	"container/heap"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+)
+
+// HyperLogLog estimates the number of distinct elements in 2^precision bytes,
+// the standard error is about 1.04/sqrt(2^precision).
+type HyperLogLog[T comparable] struct {
+	precision uint8
+	registers []uint8
+	hash      function.Func[T, uint64]
+}
+
+// NewHyperLogLog creates a sketch with precision in [4, 16], a nil hash falls back to HashOf.
+func NewHyperLogLog[T comparable](precision uint8, hash function.Func[T, uint64]) *HyperLogLog[T] {
+	if precision < 4 {
+		precision = 4
+	}
+	if precision > 16 {
+		precision = 16
+	}
+	if hash == nil {
+		hash = HashOf[T]
+	}
+	return &HyperLogLog[T]{precision: precision, registers: make([]uint8, 1<<precision), hash: hash}
+}
+
+func (hll *HyperLogLog[T]) Accept(t T) {
+	h := hll.hash.Apply(t)
+	index := h >> (64 - hll.precision)
+	rho := uint8(bits.LeadingZeros64(h<<hll.precision|1<<(hll.precision-1))) + 1
+	if rho > hll.registers[index] {
+		hll.registers[index] = rho
+	}
+}
+
+// Merge folds other into hll, both must share precision and hash.
+func (hll *HyperLogLog[T]) Merge(other *HyperLogLog[T]) {
+	helper.RequireNonNil(other)
+	if hll.precision != other.precision {
+		panic("merging HyperLogLog of different precision")
+	}
+	for i, v := range other.registers {
+		if v > hll.registers[i] {
+			hll.registers[i] = v
+		}
+	}
+}
+
+func (hll *HyperLogLog[T]) Estimate() uint64 {
+	m := float64(len(hll.registers))
+	var alpha float64
+	switch len(hll.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	sum, zeros := 0.0, 0
+	for _, v := range hll.registers {
+		sum += math.Ldexp(1, -int(v))
+		if v == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// linear counting is more accurate for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// CountDistinctApprox estimates the number of distinct elements of s with a
+// HyperLogLog of precision 14, about 0.8% standard error in 16KB.
+func CountDistinctApprox[T comparable](s Stream[T]) uint64 {
+	helper.RequireCanButNonNil(s)
+	var list []*HyperLogLog[T]
+	forEachChunk(s, func() function.Consumer[T] {
+		hll := NewHyperLogLog[T](14, nil)
+		list = append(list, hll)
+		return hll.Accept
+	})
+	res := NewHyperLogLog[T](14, nil)
+	for _, hll := range list {
+		res.Merge(hll)
+	}
+	return res.Estimate()
+}
+
+// SpaceSaving tracks the most frequent elements with a fixed number of counters.
+// Any element occurring more than Count/capacity times is guaranteed to be kept,
+// and each estimated count exceeds the true one by at most its error.
+type SpaceSaving[T comparable] struct {
+	capacity int
+	count    int
+	seq      int
+	counters map[T]*counter[T]
+	heap     counterHeap[T]
+}
+
+// counter is the estimated frequency of key, seq is when the counter was
+// taken and breaks ties between equal counts, older counters rank higher.
+type counter[T any] struct {
+	key          T
+	count, error int
+	seq, index   int
+}
+
+func (c *counter[T]) less(other *counter[T]) bool {
+	if c.count != other.count {
+		return c.count < other.count
+	}
+	return c.seq > other.seq
+}
+
+// counterHeap keeps the counter to evict, the least frequent and then the
+// most recent one, at its root.
+type counterHeap[T any] []*counter[T]
+
+func (h counterHeap[T]) Len() int           { return len(h) }
+func (h counterHeap[T]) Less(i, j int) bool { return h[i].less(h[j]) }
+func (h counterHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+func (h *counterHeap[T]) Push(x any) {
+	c := x.(*counter[T])
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+func (h *counterHeap[T]) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+func NewSpaceSaving[T comparable](capacity int) *SpaceSaving[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &SpaceSaving[T]{capacity: capacity, counters: make(map[T]*counter[T], capacity)}
+}
+
+func (ss *SpaceSaving[T]) track(key T, count, error int) {
+	c := &counter[T]{key: key, count: count, error: error, seq: ss.seq}
+	ss.seq++
+	ss.counters[key] = c
+	heap.Push(&ss.heap, c)
+}
+
+// min is the count any untracked element may have had, zero until every
+// counter is taken.
+func (ss *SpaceSaving[T]) min() int {
+	if len(ss.heap) < ss.capacity {
+		return 0
+	}
+	return ss.heap[0].count
+}
+
+func (ss *SpaceSaving[T]) Accept(t T) {
+	ss.count++
+	if c, ok := ss.counters[t]; ok {
+		c.count++
+		heap.Fix(&ss.heap, c.index)
+		return
+	}
+	if len(ss.heap) < ss.capacity {
+		ss.track(t, 1, 0)
+		return
+	}
+	// the evicted counter is reused for t
+	c := ss.heap[0]
+	delete(ss.counters, c.key)
+	c.key, c.error, c.seq = t, c.count, ss.seq
+	c.count++
+	ss.seq++
+	ss.counters[t] = c
+	heap.Fix(&ss.heap, 0)
+}
+
+// Merge adds the counters of other and keeps the capacity largest ones.
+// An element tracked by only one of the sketches may have occurred up to
+// the minimum count of the other, which is added to its count and error.
+func (ss *SpaceSaving[T]) Merge(other *SpaceSaving[T]) {
+	helper.RequireNonNil(other)
+	ownMin, otherMin := ss.min(), other.min()
+	ss.count += other.count
+	for _, c := range ss.heap {
+		if _, ok := other.counters[c.key]; !ok {
+			c.count += otherMin
+			c.error += otherMin
+		}
+	}
+	for _, c := range other.sorted() {
+		if own, ok := ss.counters[c.key]; ok {
+			own.count += c.count
+			own.error += c.error
+		} else {
+			ss.track(c.key, c.count+ownMin, c.error+ownMin)
+		}
+	}
+	heap.Init(&ss.heap)
+	for len(ss.heap) > ss.capacity {
+		delete(ss.counters, heap.Pop(&ss.heap).(*counter[T]).key)
+	}
+}
+
+// sorted returns the counters most frequent first.
+func (ss *SpaceSaving[T]) sorted() []*counter[T] {
+	list := append([]*counter[T](nil), ss.heap...)
+	sort.Slice(list, func(i, j int) bool { return list[j].less(list[i]) })
+	return list
+}
+
+func (ss *SpaceSaving[T]) Count() int {
+	return ss.count
+}
+
+// Error returns the maximum overestimation of the count of t.
+func (ss *SpaceSaving[T]) Error(t T) int {
+	if c, ok := ss.counters[t]; ok {
+		return c.error
+	}
+	return 0
+}
+
+// Top returns at most k elements with their estimated counts, most frequent
+// first, equal counts keep the order in which their counters were taken.
+func (ss *SpaceSaving[T]) Top(k int) []collections.Entry[T, int] {
+	if k <= 0 {
+		return nil
+	}
+	sorted := ss.sorted()
+	if k < len(sorted) {
+		sorted = sorted[:k]
+	}
+	list := make([]collections.Entry[T, int], 0, len(sorted))
+	for _, c := range sorted {
+		list = append(list, collections.EntryOf(c.key, c.count))
+	}
+	return list
+}
+
+// HeavyHitters returns the k most frequent elements of s with their estimated counts.
+func HeavyHitters[T comparable](s Stream[T], k int) []collections.Entry[T, int] {
+	helper.RequireCanButNonNil(s)
+	if k < 0 {
+		panic("heavy hitters count must not be negative")
+	}
+	// the extra counters keep the top k accurate when the tail is long
+	capacity := 10 * k
+	var list []*SpaceSaving[T]
+	forEachChunk(s, func() function.Consumer[T] {
+		ss := NewSpaceSaving[T](capacity)
+		list = append(list, ss)
+		return ss.Accept
+	})
+	res := NewSpaceSaving[T](capacity)
+	for _, ss := range list {
+		res.Merge(ss)
+	}
+	return res.Top(k)
+}
+
+// BloomFilter answers set membership with no false negatives and a
+// configurable rate of false positives.
+type BloomFilter[T comparable] struct {
+	bits   []uint64
+	size   uint64
+	hashes int
+	hash   function.Func[T, uint64]
+}
+
+// NewBloomFilter sizes a filter for expected elements at the false positive rate fpRate,
+// a nil hash falls back to HashOf.
+func NewBloomFilter[T comparable](expected int, fpRate float64, hash function.Func[T, uint64]) *BloomFilter[T] {
+	if expected < 1 {
+		expected = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	if hash == nil {
+		hash = HashOf[T]
+	}
+	size := uint64(math.Ceil(-float64(expected) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if size < 64 {
+		size = 64
+	}
+	hashes := int(math.Round(float64(size) / float64(expected) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &BloomFilter[T]{bits: make([]uint64, (size+63)/64), size: size, hashes: hashes, hash: hash}
+}
+
+// positions derives the probe positions by double hashing.
+func (bf *BloomFilter[T]) positions(t T, fn func(pos uint64) bool) bool {
+	h := bf.hash.Apply(t)
+	h1, h2 := h&math.MaxUint32, h>>32|1
+	for i := 0; i < bf.hashes; i++ {
+		if !fn((h1 + uint64(i)*h2) % bf.size) {
+			return false
+		}
+	}
+	return true
+}
+
+func (bf *BloomFilter[T]) Add(t T) {
+	bf.positions(t, func(pos uint64) bool {
+		bf.bits[pos/64] |= 1 << (pos % 64)
+		return true
+	})
+}
+
+// MightContain is false only if t was never added.
+func (bf *BloomFilter[T]) MightContain(t T) bool {
+	return bf.positions(t, func(pos uint64) bool {
+		return bf.bits[pos/64]&(1<<(pos%64)) != 0
+	})
+}
+
+// Merge unions other into bf, both must have been created with the same parameters.
+func (bf *BloomFilter[T]) Merge(other *BloomFilter[T]) {
+	helper.RequireNonNil(other)
+	if bf.size != other.size || bf.hashes != other.hashes {
+		panic("merging BloomFilter of different shape")
+	}
+	for i, v := range other.bits {
+		bf.bits[i] |= v
+	}
+}
+
+// DistinctApprox drops the elements already seen according to a bloom filter.
+// No duplicate is ever emitted, while roughly fpRate of the distinct elements
+// may be dropped as false positives.
+func DistinctApprox[T comparable](s Stream[T], expected int, fpRate float64) Stream[T] {
+	helper.RequireCanButNonNil(s)
+	return fromEach(func(down function.Consumer[T], more func() bool) {
+		bf := NewBloomFilter[T](expected, fpRate, nil)
+		forEachUpstream(s, more, func(t T) {
+			if !bf.MightContain(t) {
+				bf.Add(t)
+				down(t)
+			}
+		})
+	}, s)
+}
@@ -0,0 +1,14 @@
+//go:build go1.24
+
+package stream
+
+import "hash/maphash"
+
+var hashSeed = maphash.MakeSeed()
+
+// HashOf is the default hash of the probabilistic aggregations. The seed is
+// drawn once per process, so sketches hashed with it only merge with sketches
+// of the same process.
+func HashOf[T comparable](t T) uint64 {
+	return maphash.Comparable(hashSeed, t)
+}
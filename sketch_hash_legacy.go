@@ -0,0 +1,23 @@
+//go:build !go1.24
+
+package stream
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// HashOf is the default hash of the probabilistic aggregations, it hashes the
+// Go-syntax representation of t as maphash.Comparable needs go1.24.
+func HashOf[T comparable](t T) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%#v", t)
+	// splitmix64 finalizer, fnv alone spreads short keys poorly over the high bits
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,142 @@
+package stream_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/go-park/stream/support/collections"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSketch(t *testing.T) {
+	t.Run("countDistinctApprox", func(t *testing.T) {
+		var list []string
+		for i := 0; i < 50000; i++ {
+			list = append(list, fmt.Sprintf("user-%d", i%20000))
+		}
+		got := float64(stream.CountDistinctApprox(stream.From(list...)))
+		assert.LessOrEqual(t, math.Abs(got-20000), 20000*0.03)
+		// the sketches of the parallel chunks are merged into one estimate
+		got = float64(stream.CountDistinctApprox(stream.From(list...).Parallel()))
+		assert.LessOrEqual(t, math.Abs(got-20000), 20000*0.03)
+		assert.Equal(t, uint64(3), stream.CountDistinctApprox(stream.From(1, 2, 3, 2, 1)))
+		assert.Equal(t, uint64(0), stream.CountDistinctApprox(stream.From[int]()))
+	})
+
+	t.Run("hll-merge", func(t *testing.T) {
+		left := stream.NewHyperLogLog[int](12, nil)
+		right := stream.NewHyperLogLog[int](12, nil)
+		for i := 0; i < 1000; i++ {
+			left.Accept(i)
+			right.Accept(i + 500)
+		}
+		left.Merge(right)
+		assert.InDelta(t, 1500, float64(left.Estimate()), 1500*0.05)
+		// precisions out of [4, 16] are clamped
+		assert.NotPanics(t, func() { stream.NewHyperLogLog[int](0, nil).Merge(stream.NewHyperLogLog[int](4, nil)) })
+		assert.Panics(t, func() { stream.NewHyperLogLog[int](40, nil).Merge(left) })
+	})
+
+	t.Run("heavyHitters", func(t *testing.T) {
+		var list []int
+		for i := 0; i < 10000; i++ {
+			switch {
+			case i%5 == 0:
+				list = append(list, -1)
+			case i%10 == 1:
+				list = append(list, -2)
+			default:
+				list = append(list, i)
+			}
+		}
+		top := stream.HeavyHitters(stream.From(list...), 2)
+		assert.Equal(t, 2, len(top))
+		assert.Equal(t, -1, top[0].Key())
+		assert.GreaterOrEqual(t, top[0].Value(), 2000)
+		assert.Equal(t, -2, top[1].Key())
+		assert.GreaterOrEqual(t, top[1].Value(), 1000)
+		assert.Empty(t, stream.HeavyHitters(stream.From(list...), 0))
+		assert.Empty(t, stream.HeavyHitters(stream.From[int](), 2))
+		assert.Panics(t, func() { stream.HeavyHitters(stream.From(list...), -1) })
+	})
+
+	t.Run("spaceSaving-merge", func(t *testing.T) {
+		left := stream.NewSpaceSaving[string](2)
+		right := stream.NewSpaceSaving[string](2)
+		for _, v := range []string{"a", "a", "b"} {
+			left.Accept(v)
+		}
+		for _, v := range []string{"a", "c", "c", "c", "c"} {
+			right.Accept(v)
+		}
+		left.Merge(right)
+		top := left.Top(2)
+		assert.Equal(t, 8, left.Count())
+		assert.Equal(t, "c", top[0].Key())
+		assert.Equal(t, "a", top[1].Key())
+		assert.Equal(t, 3, top[1].Value())
+		// c may have been seen once by left before being evicted there
+		assert.Equal(t, 5, top[0].Value())
+		assert.Equal(t, 1, left.Error("c"))
+		assert.Equal(t, 0, left.Error("b"))
+	})
+
+	t.Run("spaceSaving-ties", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			ss := stream.NewSpaceSaving[string](3)
+			for _, v := range []string{"x", "y", "z", "w", "y"} {
+				ss.Accept(v)
+			}
+			assert.Equal(t, []collections.Entry[string, int]{
+				collections.EntryOf("y", 2),
+				collections.EntryOf("w", 2),
+				collections.EntryOf("x", 1),
+			}, ss.Top(3))
+			assert.Equal(t, 1, ss.Error("w"))
+			assert.Empty(t, ss.Top(0))
+			assert.Empty(t, ss.Top(-1))
+		}
+	})
+
+	t.Run("distinctApprox", func(t *testing.T) {
+		list := []int{1, 2, 3, 1, 2, 3, 4, 4, 5, 6, 7, 8, 9, 9}
+		s := stream.DistinctApprox(stream.From(list...), 100, 0.001)
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, s.ToSlice())
+		assert.Empty(t, stream.DistinctApprox(stream.From[int](), 100, 0.001).ToSlice())
+		// an invalid fpRate falls back to 1%
+		s = stream.DistinctApprox(stream.From(list...), 100, 2)
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, s.ToSlice())
+	})
+
+	t.Run("distinctApprox-error", func(t *testing.T) {
+		lines := stream.FromLines(&failingReader{data: "a\nb\na\n", err: errTransient})
+		s := stream.DistinctApprox(lines, 100, 0.001)
+		assert.Equal(t, []string{"a", "b"}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errTransient)
+	})
+
+	t.Run("bloom", func(t *testing.T) {
+		bf := stream.NewBloomFilter[int](1000, 0.01, nil)
+		other := stream.NewBloomFilter[int](1000, 0.01, nil)
+		for i := 0; i < 1000; i++ {
+			bf.Add(i)
+			other.Add(-i - 1)
+		}
+		bf.Merge(other)
+		falsePositive := 0
+		for i := 0; i < 1000; i++ {
+			assert.Equal(t, true, bf.MightContain(i))
+			assert.Equal(t, true, bf.MightContain(-i-1))
+			if bf.MightContain(i + 10000) {
+				falsePositive++
+			}
+		}
+		// two merged sets of 1000 overfill the filter, the rate stays bounded though
+		assert.Less(t, falsePositive, 200)
+		// an fpRate out of (0, 1) falls back to 1%
+		assert.NotPanics(t, func() { stream.NewBloomFilter[int](1000, 0, nil).Merge(stream.NewBloomFilter[int](1000, 0.01, nil)) })
+		assert.NotPanics(t, func() { stream.NewBloomFilter[int](1000, 1, nil).Merge(stream.NewBloomFilter[int](1000, 0.01, nil)) })
+	})
+}
@@ -0,0 +1,209 @@
+package stream
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-park/stream/support/function"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// rowsIterator scans the rows of a query one at a time, closing them once
+// they are exhausted, fail or the stream is closed.
+type rowsIterator[T any] struct {
+	rows  *sql.Rows
+	scan  func(*sql.Rows) (T, error)
+	next  T
+	ready bool
+	done  bool
+	err   error
+}
+
+func (it *rowsIterator[T]) HasNext() bool {
+	if it.ready || it.done {
+		return it.ready
+	}
+	if !it.rows.Next() {
+		it.done, it.err = true, it.rows.Err()
+		it.Close()
+		return false
+	}
+	t, err := it.scan(it.rows)
+	if err != nil {
+		it.done, it.err = true, err
+		it.Close()
+		return false
+	}
+	it.next, it.ready = t, true
+	return true
+}
+
+func (it *rowsIterator[T]) Next() T {
+	var t T
+	if it.HasNext() {
+		t, it.ready = it.next, false
+	}
+	return t
+}
+
+func (it *rowsIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for it.HasNext() {
+		fn(it.Next())
+	}
+}
+
+func (it *rowsIterator[T]) Err() error {
+	return it.err
+}
+
+func (it *rowsIterator[T]) Close() error {
+	return it.rows.Close()
+}
+
+// scanRow scans a row into a T. A struct T gets the columns in the fields
+// going by their name, unmatched columns are dropped. Any other T, as well
+// as time.Time and sql.Scanner structs, gets the only column.
+func scanRow[T any]() func(*sql.Rows) (T, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct || typ == timeType || reflect.PointerTo(typ).Implements(scannerType) {
+		return func(rows *sql.Rows) (T, error) {
+			var t T
+			err := rows.Scan(&t)
+			return t, err
+		}
+	}
+	fields := structFields(typ, "db")
+	var columns []*structField
+	return func(rows *sql.Rows) (T, error) {
+		var t T
+		if columns == nil {
+			names, err := rows.Columns()
+			if err != nil {
+				return t, err
+			}
+			columns = make([]*structField, len(names))
+			for i, name := range names {
+				columns[i] = fieldNamed(fields, name)
+			}
+		}
+		v := reflect.ValueOf(&t).Elem()
+		dest := make([]any, len(columns))
+		for i, f := range columns {
+			if f == nil {
+				dest[i] = new(any)
+				continue
+			}
+			dest[i] = v.FieldByIndex(f.index).Addr().Interface()
+		}
+		err := rows.Scan(dest...)
+		return t, err
+	}
+}
+
+// FromRows yields a T per row of rows, scanning the columns into the
+// fields of a struct T by their db tag or else their name, in any case.
+// A T that is no struct, or is a time.Time or an sql.Scanner, gets the
+// only column. The rows are closed once the stream is exhausted, fails
+// or is closed, a failure is reported by Err.
+func FromRows[T any](rows *sql.Rows) Stream[T] {
+	return FromRowsScan(rows, scanRow[T]())
+}
+
+// FromRowsScan yields a T per row of rows, as scan reads it.
+func FromRowsScan[T any](rows *sql.Rows, scan func(*sql.Rows) (T, error)) Stream[T] {
+	return Builder[T]().iterator(&rowsIterator[T]{rows: rows, scan: scan}).Build()
+}
+
+// BatchInsertOptions configures ToBatchInsert.
+type BatchInsertOptions struct {
+	// Table is the table inserted into, written into the statement as is.
+	Table string
+	// Size is the number of rows inserted per statement, 100 when zero.
+	Size int
+	// Placeholder writes the n-th parameter of a statement, counting from 1.
+	// It is "?" when nil, use DollarPlaceholder for PostgreSQL.
+	Placeholder func(n int) string
+}
+
+// DollarPlaceholder writes the n-th parameter as $n.
+func DollarPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// insertStatement writes an insert of rows rows of columns into table.
+func insertStatement(table string, columns []string, rows int, placeholder func(int) string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+	n := 0
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for c := range columns {
+			if c > 0 {
+				sb.WriteString(", ")
+			}
+			n++
+			sb.WriteString(placeholder(n))
+		}
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
+
+// ToBatchInsert inserts the elements of s into opts.Table within a single
+// transaction, a statement per Chunk of opts.Size elements. The fields of
+// the struct T are the columns, named by their db tag or else their name.
+// On the first error the transaction is rolled back and the error of the
+// statement or of s returned.
+func ToBatchInsert[T any](ctx context.Context, db *sql.DB, s Stream[T], opts BatchInsertOptions) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return fmt.Errorf("stream: batch insert needs a struct, not %v", typ)
+	}
+	fields := structFields(typ, "db")
+	if len(fields) == 0 {
+		return fmt.Errorf("stream: batch insert needs exported fields in %v", typ)
+	}
+	if opts.Size <= 0 {
+		opts.Size = 100
+	}
+	if opts.Placeholder == nil {
+		opts.Placeholder = func(int) string { return "?" }
+	}
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.name
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	full := insertStatement(opts.Table, columns, opts.Size, opts.Placeholder)
+	args := make([]any, 0, opts.Size*len(fields))
+	err = forEachErr(Chunk(s, opts.Size), func(chunk []T) error {
+		args = args[:0]
+		for _, t := range chunk {
+			v := reflect.ValueOf(t)
+			for _, f := range fields {
+				args = append(args, v.FieldByIndex(f.index).Interface())
+			}
+		}
+		query := full
+		if len(chunk) < opts.Size {
+			query = insertStatement(opts.Table, columns, len(chunk), opts.Placeholder)
+		}
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
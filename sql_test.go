@@ -0,0 +1,289 @@
+package stream_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+var errFake = errors.New("fake failure")
+
+// fakeDB is the state behind one data source name of the fake driver.
+// Queries answer with rows, statements containing "fail" fail.
+type fakeDB struct {
+	mu      sync.Mutex
+	columns []string
+	rows    [][]driver.Value
+	failAt  int
+	closed  int
+	execs   []string
+	args    [][]driver.Value
+	commits int
+	aborts  int
+}
+
+type fakeDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*fakeDB
+}
+
+var fakes = &fakeDriver{dbs: make(map[string]*fakeDB)}
+
+func init() {
+	sql.Register("streamfake", fakes)
+}
+
+// openFake opens a database backed by a fresh fakeDB.
+func openFake(t *testing.T) (*sql.DB, *fakeDB) {
+	fake := &fakeDB{failAt: -1}
+	fakes.mu.Lock()
+	fakes.dbs[t.Name()] = fake
+	fakes.mu.Unlock()
+	db, err := sql.Open("streamfake", t.Name())
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return &fakeConn{db: d.dbs[name]}, nil
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{db: c.db}, nil }
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx *fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.aborts++
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "fail") {
+		return nil, errFake
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.execs = append(s.db.execs, s.query)
+	s.db.args = append(s.db.args, args)
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{db: s.db}, nil
+}
+
+type fakeRows struct {
+	db  *fakeDB
+	pos int
+}
+
+func (r *fakeRows) Columns() []string { return r.db.columns }
+
+func (r *fakeRows) Close() error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	r.db.closed++
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos == r.db.failAt {
+		return errFake
+	}
+	if r.pos == len(r.db.rows) {
+		return io.EOF
+	}
+	copy(dest, r.db.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+type account struct {
+	ID    int64   `db:"id"`
+	Owner string  `db:"owner_name"`
+	Email *string `db:"email"`
+	Score float64
+}
+
+func TestFromRows(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		db, fake := openFake(t)
+		fake.columns = []string{"id", "owner_name", "email", "SCORE", "ignored"}
+		fake.rows = [][]driver.Value{
+			{int64(1), "ann", "ann@example.com", 1.5, "x"},
+			{int64(2), []byte("bob"), nil, 2.0, "y"},
+		}
+		rows, err := db.Query("select")
+		assert.NoError(t, err)
+		s := stream.FromRows[account](rows)
+		list := s.ToSlice()
+		assert.NoError(t, s.Err())
+		email := "ann@example.com"
+		assert.Equal(t, []account{
+			{ID: 1, Owner: "ann", Email: &email, Score: 1.5},
+			{ID: 2, Owner: "bob", Score: 2},
+		}, list)
+		assert.Equal(t, 1, fake.closed)
+	})
+	t.Run("scalar", func(t *testing.T) {
+		db, fake := openFake(t)
+		fake.columns = []string{"id"}
+		fake.rows = [][]driver.Value{{int64(3)}, {int64(1)}, {int64(2)}}
+		rows, err := db.Query("select")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3}, stream.Sort(stream.FromRows[int64](rows)).ToSlice())
+	})
+	t.Run("scan func", func(t *testing.T) {
+		db, fake := openFake(t)
+		fake.columns = []string{"a", "b"}
+		fake.rows = [][]driver.Value{{"x", int64(1)}, {"y", int64(2)}}
+		rows, err := db.Query("select")
+		assert.NoError(t, err)
+		s := stream.FromRowsScan(rows, func(rows *sql.Rows) (string, error) {
+			var a string
+			var b int
+			err := rows.Scan(&a, &b)
+			return strings.Repeat(a, b), err
+		})
+		assert.Equal(t, []string{"x", "yy"}, s.ToSlice())
+	})
+	t.Run("early exit closes", func(t *testing.T) {
+		db, fake := openFake(t)
+		fake.columns = []string{"id"}
+		fake.rows = [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}}
+		rows, err := db.Query("select")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1}, stream.FromRows[int64](rows).Limit(1).ToSlice())
+		assert.Equal(t, 1, fake.closed)
+	})
+	t.Run("errors", func(t *testing.T) {
+		db, fake := openFake(t)
+		fake.columns = []string{"id"}
+		fake.rows = [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}}
+		fake.failAt = 2
+		rows, err := db.Query("select")
+		assert.NoError(t, err)
+		s := stream.FromRows[int64](rows)
+		assert.Equal(t, []int64{1, 2}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), errFake)
+
+		fake.failAt = -1
+		fake.rows = [][]driver.Value{{"x"}}
+		rows, err = db.Query("select")
+		assert.NoError(t, err)
+		bad := stream.FromRows[int64](rows)
+		assert.Empty(t, bad.ToSlice())
+		assert.Error(t, bad.Err())
+		assert.Equal(t, 2, fake.closed)
+	})
+}
+
+// repeatReader reads line over and over.
+type repeatReader struct {
+	line string
+	pos  int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.line[r.pos%len(r.line)]
+		r.pos++
+	}
+	return len(p), nil
+}
+
+func TestToBatchInsert(t *testing.T) {
+	email := "ann@example.com"
+	accounts := []account{
+		{1, "ann", &email, 1}, {2, "bob", nil, 2}, {3, "cat", nil, 3}, {4, "dan", nil, 4}, {5, "eve", nil, 5},
+	}
+	t.Run("chunks", func(t *testing.T) {
+		db, fake := openFake(t)
+		err := stream.ToBatchInsert(context.Background(), db, stream.From(accounts...), stream.BatchInsertOptions{
+			Table: "accounts",
+			Size:  2,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"INSERT INTO accounts (id, owner_name, email, Score) VALUES (?, ?, ?, ?), (?, ?, ?, ?)",
+			"INSERT INTO accounts (id, owner_name, email, Score) VALUES (?, ?, ?, ?), (?, ?, ?, ?)",
+			"INSERT INTO accounts (id, owner_name, email, Score) VALUES (?, ?, ?, ?)",
+		}, fake.execs)
+		assert.Equal(t, []driver.Value{int64(1), "ann", "ann@example.com", float64(1), int64(2), "bob", nil, float64(2)}, fake.args[0])
+		assert.Equal(t, 1, fake.commits)
+	})
+	t.Run("placeholders", func(t *testing.T) {
+		db, fake := openFake(t)
+		err := stream.ToBatchInsert(context.Background(), db, stream.From(accounts[:2]...), stream.BatchInsertOptions{
+			Table:       "accounts",
+			Placeholder: stream.DollarPlaceholder,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"INSERT INTO accounts (id, owner_name, email, Score) VALUES ($1, $2, $3, $4), ($5, $6, $7, $8)",
+		}, fake.execs)
+	})
+	t.Run("rollback", func(t *testing.T) {
+		db, fake := openFake(t)
+		err := stream.ToBatchInsert(context.Background(), db, stream.From(accounts...), stream.BatchInsertOptions{
+			Table: "fail",
+		})
+		assert.ErrorIs(t, err, errFake)
+		assert.Equal(t, 0, fake.commits)
+		assert.Equal(t, 1, fake.aborts)
+
+		malformed := stream.FromJSONLines[account](strings.NewReader("{\"ID\": 1}\n{"))
+		err = stream.ToBatchInsert(context.Background(), db, malformed, stream.BatchInsertOptions{Table: "accounts"})
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, 2, fake.aborts)
+
+		// a failed statement stops reading the source
+		endless := stream.FromJSONLines[account](&repeatReader{line: "{\"ID\": 1}\n"})
+		err = stream.ToBatchInsert(context.Background(), db, endless, stream.BatchInsertOptions{Table: "fail", Size: 2})
+		assert.ErrorIs(t, err, errFake)
+		assert.Equal(t, 3, fake.aborts)
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		db, _ := openFake(t)
+		assert.Error(t, stream.ToBatchInsert(context.Background(), db, stream.From(1, 2), stream.BatchInsertOptions{}))
+		type hidden struct{ id int }
+		err := stream.ToBatchInsert(context.Background(), db, stream.From(hidden{1}), stream.BatchInsertOptions{Table: "t"})
+		assert.ErrorContains(t, err, "exported fields")
+	})
+}
@@ -0,0 +1,185 @@
+package stream
+
+import (
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+	"github.com/go-park/stream/support/optional"
+	"github.com/go-park/stream/support/routine"
+)
+
+// upstream is what a stage needs of the streams it is built on, whatever
+// their element type.
+type upstream interface {
+	Close()
+	Err() error
+}
+
+// eachIterator adapts a push style producer to collections.Iterator.
+// Draining it streams elements straight through and tells the producer once
+// no more are wanted, HasNext and Next buffer whatever the producer has left
+// on first use. Closing it closes the upstreams, whose errors it reports.
+type eachIterator[T any] struct {
+	each      func(down function.Consumer[T], more func() bool)
+	upstreams []upstream
+	rest      collections.Iterator[T]
+}
+
+func (iter *eachIterator[T]) buffered() collections.Iterator[T] {
+	if iter.rest == nil {
+		var list []T
+		iter.each(func(t T) { list = append(list, t) }, func() bool { return true })
+		iter.rest = collections.IterableSlice(list...)
+	}
+	return iter.rest
+}
+
+func (iter *eachIterator[T]) HasNext() bool {
+	return iter.buffered().HasNext()
+}
+
+func (iter *eachIterator[T]) Next() T {
+	return iter.buffered().Next()
+}
+
+func (iter *eachIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	iter.forEachWhile(func(t T) bool {
+		fn(t)
+		return true
+	})
+}
+
+func (iter *eachIterator[T]) forEachWhile(fn func(T) bool) {
+	if iter.rest != nil {
+		for iter.rest.HasNext() && fn(iter.rest.Next()) {
+		}
+		return
+	}
+	iter.rest = collections.IterableSlice[T]()
+	more := true
+	iter.each(func(t T) {
+		if more {
+			more = fn(t)
+		}
+	}, func() bool { return more })
+}
+
+func (iter *eachIterator[T]) Err() error {
+	for _, s := range iter.upstreams {
+		if err := s.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (iter *eachIterator[T]) Close() error {
+	for _, s := range iter.upstreams {
+		s.Close()
+	}
+	return nil
+}
+
+// fromEach builds a stream whose elements are pushed by each once a terminal
+// operation runs, so stages built on top of other streams stay lazy. more
+// reports whether the stream still wants elements, the upstreams are read
+// with forEachUpstream so that they stop once it does not.
+func fromEach[T any](each func(down function.Consumer[T], more func() bool), upstreams ...upstream) Stream[T] {
+	return Builder[T]().iterator(&eachIterator[T]{each: each, upstreams: upstreams}).Build()
+}
+
+// forEachUpstream drains the upstream s of a stage into fn, sequentially as
+// the stages keep state. s is closed as soon as more turns false, which stops
+// it where it is.
+func forEachUpstream[T any](s Stream[T], more func() bool, fn function.Consumer[T]) {
+	if !more() {
+		s.Close()
+		return
+	}
+	s.Sequential().ForEach(func(t T) {
+		if !more() {
+			// the simple engine may still deliver what was in flight
+			return
+		}
+		fn(t)
+		if !more() {
+			s.Close()
+		}
+	})
+}
+
+// pull turns s into a pull style iterator. The stream is drained by a separate
+// goroutine; stop closes s and waits for the goroutine when the caller is done
+// before the end.
+func pull[T any](s Stream[T]) (next func() (T, bool), stop func()) {
+	ch := make(chan T)
+	done := make(chan struct{})
+	routine.Run(func() {
+		defer close(ch)
+		s.Sequential().ForEach(func(t T) {
+			select {
+			case ch <- t:
+			case <-done:
+				s.Close()
+			}
+		})
+	})
+	stopped := false
+	next = func() (T, bool) {
+		var v T
+		if stopped {
+			return v, false
+		}
+		v, ok := <-ch
+		return v, ok
+	}
+	stop = func() {
+		if !stopped {
+			stopped = true
+			close(done)
+			for range ch {
+			}
+		}
+	}
+	return next, stop
+}
+
+// chunked is implemented by the pipelines able to split their elements into
+// contiguous chunks, fac is called once per chunk in encounter order.
+type chunked[T any] interface {
+	forEachChunk(fac func() function.Consumer[T])
+}
+
+func forEachChunk[T any](s Stream[T], fac func() function.Consumer[T]) {
+	if c, ok := s.(chunked[T]); ok {
+		c.forEachChunk(fac)
+		return
+	}
+	s.ForEach(fac())
+}
+
+// reduceOp seeds the accumulator with the first element it receives, acc
+// is then applied to the next element and the accumulated value, in that order.
+func reduceOp[T any](acc function.BiFunc[T, T, T]) (*optional.Value[T], function.Consumer[T]) {
+	val := optional.EmptyVal[T]()
+	return &val, func(v T) {
+		val.IfNotEmptyOrElse(
+			func(t T) { val = optional.ValOf(acc.Apply(v, t)) },
+			func() { val = optional.ValOf(v) })
+	}
+}
+
+// reduceChunks reduces every chunk of s on its own and combines the partial
+// results in encounter order, so every engine agrees with a sequential left fold.
+func reduceChunks[T any](s Stream[T], acc function.BiFunc[T, T, T]) optional.Value[T] {
+	var list []*optional.Value[T]
+	forEachChunk(s, func() function.Consumer[T] {
+		val, op := reduceOp(acc)
+		list = append(list, val)
+		return op
+	})
+	val, op := reduceOp(acc)
+	for _, item := range list {
+		item.IfNotEmpty(op)
+	}
+	return *val
+}
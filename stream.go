@@ -35,4 +35,7 @@ type Stream[T any] interface {
 	AllMatch(pred function.Predicate[T]) bool
 	NoneMatch(pred function.Predicate[T]) bool
 	FindAny() optional.Value[T]
+	Explain() Plan
+	// Err returns the error which ended the source early, once a terminal operation returned.
+	Err() error
 }
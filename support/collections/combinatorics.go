@@ -0,0 +1,158 @@
+package collections
+
+import "github.com/go-park/stream/support/function"
+
+// indexIterator walks a sequence of index tuples, each tuple is turned into
+// a freshly allocated element by pick, so callers may keep what they receive.
+type indexIterator[T any] struct {
+	indices []int
+	done    bool
+	pick    func(indices []int) []T
+	advance func(indices []int) ([]int, bool)
+}
+
+func (iter *indexIterator[T]) HasNext() bool {
+	return !iter.done
+}
+
+func (iter *indexIterator[T]) Next() []T {
+	if iter.done {
+		return nil
+	}
+	v := iter.pick(iter.indices)
+	var ok bool
+	iter.indices, ok = iter.advance(iter.indices)
+	iter.done = !ok
+	return v
+}
+
+func (iter *indexIterator[T]) ForEachRemaining(fn function.Consumer[[]T]) {
+	for iter.HasNext() {
+		fn(iter.Next())
+	}
+}
+
+func pickFrom[T any](list []T) func(indices []int) []T {
+	return func(indices []int) []T {
+		res := make([]T, len(indices))
+		for i, index := range indices {
+			res[i] = list[index]
+		}
+		return res
+	}
+}
+
+func identity(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+// nextPermutation rearranges indices into the next lexicographic permutation.
+func nextPermutation(indices []int) ([]int, bool) {
+	i := len(indices) - 2
+	for i >= 0 && indices[i] >= indices[i+1] {
+		i--
+	}
+	if i < 0 {
+		return indices, false
+	}
+	j := len(indices) - 1
+	for indices[j] <= indices[i] {
+		j--
+	}
+	indices[i], indices[j] = indices[j], indices[i]
+	for l, r := i+1, len(indices)-1; l < r; l, r = l+1, r-1 {
+		indices[l], indices[r] = indices[r], indices[l]
+	}
+	return indices, true
+}
+
+// nextCombination moves indices to the next k-combination of n in lexicographic order.
+func nextCombination(indices []int, n int) ([]int, bool) {
+	k := len(indices)
+	i := k - 1
+	for i >= 0 && indices[i] == n-k+i {
+		i--
+	}
+	if i < 0 {
+		return indices, false
+	}
+	indices[i]++
+	for j := i + 1; j < k; j++ {
+		indices[j] = indices[j-1] + 1
+	}
+	return indices, true
+}
+
+// Permutations yields every ordering of list by position, n! elements in lexicographic order of positions.
+func Permutations[T any](list []T) Iterator[[]T] {
+	return &indexIterator[T]{
+		indices: identity(len(list)),
+		pick:    pickFrom(list),
+		advance: nextPermutation,
+	}
+}
+
+// Combinations yields every k elements subset of list keeping the list order.
+// It yields nothing when k is negative or larger than list.
+func Combinations[T any](list []T, k int) Iterator[[]T] {
+	if k < 0 || k > len(list) {
+		return &indexIterator[T]{done: true}
+	}
+	return &indexIterator[T]{
+		indices: identity(k),
+		pick:    pickFrom(list),
+		advance: func(indices []int) ([]int, bool) { return nextCombination(indices, len(list)) },
+	}
+}
+
+// PowerSet yields every subset of list, by increasing size.
+func PowerSet[T any](list []T) Iterator[[]T] {
+	return &indexIterator[T]{
+		indices: identity(0),
+		pick:    pickFrom(list),
+		advance: func(indices []int) ([]int, bool) {
+			if next, ok := nextCombination(indices, len(list)); ok {
+				return next, true
+			}
+			if len(indices) == len(list) {
+				return indices, false
+			}
+			return identity(len(indices) + 1), true
+		},
+	}
+}
+
+// CartesianProduct yields every tuple taking one element of each list, the last list varying fastest.
+func CartesianProduct[T any](lists ...[]T) Iterator[[]T] {
+	done := false
+	for _, list := range lists {
+		if len(list) == 0 {
+			done = true
+		}
+	}
+	return &indexIterator[T]{
+		indices: make([]int, len(lists)),
+		done:    done,
+		pick: func(indices []int) []T {
+			res := make([]T, len(indices))
+			for i, index := range indices {
+				res[i] = lists[i][index]
+			}
+			return res
+		},
+		advance: func(indices []int) ([]int, bool) {
+			for i := len(indices) - 1; i >= 0; i-- {
+				indices[i]++
+				if indices[i] < len(lists[i]) {
+					return indices, true
+				}
+				indices[i] = 0
+			}
+			return indices, false
+		},
+	}
+}
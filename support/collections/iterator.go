@@ -1,6 +1,8 @@
 package collections
 
 import (
+	"context"
+
 	"github.com/go-park/stream/internal/helper"
 	"github.com/go-park/stream/support/function"
 )
@@ -32,7 +34,8 @@ func (iter iterator[T]) ForEachRemaining(fn function.Consumer[T]) {
 }
 
 type iterableSlice[T any] struct {
-	slice []T
+	slice           []T
+	characteristics Characteristics
 }
 
 func (s *iterableSlice[T]) HasNext() bool {
@@ -58,38 +61,92 @@ func (s *iterableSlice[T]) ForEachRemaining(fn function.Consumer[T]) {
 	}
 }
 
+func (s *iterableSlice[T]) TryAdvance(fn function.Consumer[T]) bool {
+	return tryAdvance[T](s, fn)
+}
+
+func (s *iterableSlice[T]) TrySplit() Spliterator[T] {
+	if len(s.slice) < 2 {
+		return nil
+	}
+	mid := len(s.slice) / 2
+	prefix := &iterableSlice[T]{slice: s.slice[:mid:mid], characteristics: s.characteristics}
+	s.slice = s.slice[mid:]
+	return prefix
+}
+
+func (s *iterableSlice[T]) EstimateSize() int {
+	return len(s.slice)
+}
+
+func (s *iterableSlice[T]) Truncate(n int) {
+	if n < len(s.slice) {
+		s.slice = s.slice[:n]
+	}
+}
+
+func (s *iterableSlice[T]) Characteristics() Characteristics {
+	return s.characteristics
+}
+
+// IterableSlice iterates list in place, it is not IMMUTABLE as the caller
+// may still change list.
 func IterableSlice[T any](list ...T) Iterator[T] {
-	return &iterableSlice[T]{list}
+	return &iterableSlice[T]{slice: list, characteristics: SIZED | ORDERED}
 }
 
 func IterableChan[T any](ch chan T) Iterator[T] {
-	// escape to heap
-	ready := false
-	var value T
-	return iterator[T](
-		func() (func() bool, function.Supplier[T]) {
-			hasNext := func() bool {
-				if ready {
-					return true
-				}
-				v, ok := <-ch
-				if ok {
-					ready = true
-					value = v
-				}
-				return false
-			}
-			next := func() T {
-				defer func() { ready = false }()
-				var v T
-				if hasNext() {
-					v = value
-				}
-				return v
-			}
-			return hasNext, next
-		},
-	)
+	return IterableChanContext(context.Background(), ch)
+}
+
+type chanIterator[T any] struct {
+	ctx   context.Context
+	ch    <-chan T
+	value T
+	ready bool
+	done  bool
+}
+
+// IterableChanContext receives from ch until it is closed or ctx is done.
+// HasNext blocks until a value arrives.
+func IterableChanContext[T any](ctx context.Context, ch <-chan T) Iterator[T] {
+	return &chanIterator[T]{ctx: ctx, ch: ch}
+}
+
+func (iter *chanIterator[T]) HasNext() bool {
+	if iter.ready {
+		return true
+	}
+	if iter.done || iter.ctx.Err() != nil {
+		iter.done = true
+		return false
+	}
+	select {
+	case v, ok := <-iter.ch:
+		if !ok {
+			iter.done = true
+			return false
+		}
+		iter.value, iter.ready = v, true
+		return true
+	case <-iter.ctx.Done():
+		iter.done = true
+		return false
+	}
+}
+
+func (iter *chanIterator[T]) Next() T {
+	var v T
+	if iter.HasNext() {
+		v, iter.value, iter.ready = iter.value, v, false
+	}
+	return v
+}
+
+func (iter *chanIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for iter.HasNext() {
+		fn(iter.Next())
+	}
 }
 
 func Iterable[T any](iter iterator[T]) Iterator[T] {
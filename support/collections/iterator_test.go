@@ -1,6 +1,7 @@
 package collections_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/go-park/stream/support/collections"
@@ -27,3 +28,49 @@ func TestIterator(t *testing.T) {
 	assert.Equal(t, 0, iter2.Next())
 	assert.Equal(t, false, iter2.HasNext())
 }
+
+func TestSpliterator(t *testing.T) {
+	drain := func(sp collections.Spliterator[int]) []int {
+		var res []int
+		for sp.TryAdvance(func(v int) { res = append(res, v) }) {
+		}
+		return res
+	}
+	t.Run("slice", func(t *testing.T) {
+		sp := collections.IterableSlice(1, 2, 3, 4, 5).(collections.Spliterator[int])
+		assert.True(t, sp.Characteristics().Has(collections.SIZED|collections.ORDERED))
+		prefix := sp.TrySplit()
+		assert.Equal(t, 2, prefix.EstimateSize())
+		assert.Equal(t, 3, sp.EstimateSize())
+		assert.Equal(t, []int{1, 2}, drain(prefix))
+		assert.Equal(t, []int{3, 4, 5}, drain(sp))
+		assert.Nil(t, sp.TrySplit())
+	})
+	t.Run("range", func(t *testing.T) {
+		sp := collections.IterableRange(0, 10, 2, false).(collections.Spliterator[int])
+		assert.True(t, sp.Characteristics().Has(collections.SIZED|collections.SORTED))
+		prefix := sp.TrySplit()
+		assert.Equal(t, []int{0, 2}, drain(prefix))
+		assert.Equal(t, []int{4, 6, 8}, drain(sp))
+	})
+}
+
+func TestIterableChan(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	iter := collections.IterableChan(ch)
+	assert.True(t, iter.HasNext())
+	assert.True(t, iter.HasNext())
+	assert.Equal(t, 1, iter.Next())
+	var rest []int
+	iter.ForEachRemaining(func(v int) { rest = append(rest, v) })
+	assert.Equal(t, []int{2, 3}, rest)
+	assert.False(t, iter.HasNext())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, collections.IterableChanContext(ctx, make(chan int)).HasNext())
+}
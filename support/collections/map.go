@@ -7,6 +7,10 @@ type Entry[K comparable, V any] struct {
 	value V
 }
 
+func EntryOf[K comparable, V any](key K, value V) Entry[K, V] {
+	return Entry[K, V]{key: key, value: value}
+}
+
 func (en Entry[K, V]) Key() K {
 	return en.key
 }
@@ -0,0 +1,18 @@
+package collections
+
+type Pair[L, R any] struct {
+	left  L
+	right R
+}
+
+func PairOf[L, R any](left L, right R) Pair[L, R] {
+	return Pair[L, R]{left: left, right: right}
+}
+
+func (p Pair[L, R]) Left() L {
+	return p.left
+}
+
+func (p Pair[L, R]) Right() R {
+	return p.right
+}
@@ -0,0 +1,207 @@
+package collections
+
+import (
+	"math"
+
+	"github.com/go-park/stream/support/function"
+	"golang.org/x/exp/constraints"
+)
+
+type rangeIterator[T constraints.Integer] struct {
+	cur   T
+	step  T
+	index uint64
+	last  uint64
+	done  bool
+}
+
+// IterableRange iterates from start towards end by step, step may be negative.
+// The number of elements is computed upfront, so the iteration never overflows
+// even when end is the extreme value of T.
+func IterableRange[T constraints.Integer](start, end, step T, inclusive bool) Iterator[T] {
+	if step == 0 {
+		panic("range step must not be zero")
+	}
+	iter := &rangeIterator[T]{cur: start, step: step}
+	var diff, abs uint64
+	if step > 0 {
+		if start > end {
+			iter.done = true
+			return iter
+		}
+		diff, abs = uint64(end)-uint64(start), uint64(step)
+	} else {
+		if start < end {
+			iter.done = true
+			return iter
+		}
+		// the conversions sign extend, the modular differences are exact
+		diff, abs = uint64(start)-uint64(end), -uint64(step)
+	}
+	if !inclusive {
+		if diff == 0 {
+			iter.done = true
+			return iter
+		}
+		diff--
+	}
+	iter.last = diff / abs
+	return iter
+}
+
+func (iter *rangeIterator[T]) HasNext() bool {
+	return !iter.done
+}
+
+func (iter *rangeIterator[T]) Next() T {
+	var v T
+	if iter.done {
+		return v
+	}
+	v = iter.cur
+	if iter.index == iter.last {
+		iter.done = true
+	} else {
+		iter.index++
+		iter.cur += iter.step
+	}
+	return v
+}
+
+func (iter *rangeIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for iter.HasNext() {
+		fn(iter.Next())
+	}
+}
+
+func (iter *rangeIterator[T]) TryAdvance(fn function.Consumer[T]) bool {
+	return tryAdvance[T](iter, fn)
+}
+
+func (iter *rangeIterator[T]) TrySplit() Spliterator[T] {
+	if iter.done || iter.last-iter.index < 1 {
+		return nil
+	}
+	half := (iter.last - iter.index + 1) / 2
+	prefix := &rangeIterator[T]{cur: iter.cur, step: iter.step, last: half - 1}
+	// wrapping arithmetic lands on the right value, it is within range
+	iter.cur += T(half) * iter.step
+	iter.last -= iter.index + half
+	iter.index = 0
+	return prefix
+}
+
+func (iter *rangeIterator[T]) Truncate(n int) {
+	if iter.done {
+		return
+	}
+	if n <= 0 {
+		iter.done = true
+		return
+	}
+	if uint64(n-1) < iter.last-iter.index {
+		iter.last = iter.index + uint64(n-1)
+	}
+}
+
+// Characteristics leaves out SIZED while more elements remain than an int
+// counts, EstimateSize is then clamped.
+func (iter *rangeIterator[T]) Characteristics() Characteristics {
+	c := ORDERED | DISTINCT | IMMUTABLE
+	if iter.done || iter.last-iter.index < math.MaxInt {
+		c |= SIZED
+	}
+	if iter.step > 0 {
+		c |= SORTED
+	}
+	return c
+}
+
+func (iter *rangeIterator[T]) EstimateSize() int {
+	if iter.done {
+		return 0
+	}
+	if remain := iter.last - iter.index; remain < math.MaxInt {
+		return int(remain + 1)
+	}
+	return math.MaxInt
+}
+
+type floatRangeIterator[T constraints.Float] struct {
+	start, end T
+	index      int
+	count      int
+	// stop is where this iterator ends, count only scales the values
+	stop int
+}
+
+// IterableFloatRange yields count evenly spaced values from start to end inclusive.
+// Every value is computed from its index, so no rounding error accumulates.
+func IterableFloatRange[T constraints.Float](start, end T, count int) Iterator[T] {
+	if count < 0 {
+		count = 0
+	}
+	return &floatRangeIterator[T]{start: start, end: end, count: count, stop: count}
+}
+
+func (iter *floatRangeIterator[T]) HasNext() bool {
+	return iter.index < iter.stop
+}
+
+func (iter *floatRangeIterator[T]) Next() T {
+	var v T
+	switch {
+	case iter.index >= iter.stop:
+		return v
+	case iter.index == 0:
+		v = iter.start
+	case iter.index == iter.count-1:
+		v = iter.end
+	default:
+		v = iter.start + (iter.end-iter.start)*T(iter.index)/T(iter.count-1)
+	}
+	iter.index++
+	return v
+}
+
+func (iter *floatRangeIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for iter.HasNext() {
+		fn(iter.Next())
+	}
+}
+
+func (iter *floatRangeIterator[T]) TryAdvance(fn function.Consumer[T]) bool {
+	return tryAdvance[T](iter, fn)
+}
+
+func (iter *floatRangeIterator[T]) TrySplit() Spliterator[T] {
+	if iter.stop-iter.index < 2 {
+		return nil
+	}
+	mid := iter.index + (iter.stop-iter.index)/2
+	prefix := *iter
+	prefix.stop = mid
+	iter.index = mid
+	return &prefix
+}
+
+func (iter *floatRangeIterator[T]) EstimateSize() int {
+	return iter.stop - iter.index
+}
+
+func (iter *floatRangeIterator[T]) Truncate(n int) {
+	if n < 0 {
+		n = 0
+	}
+	if n < iter.stop-iter.index {
+		iter.stop = iter.index + n
+	}
+}
+
+func (iter *floatRangeIterator[T]) Characteristics() Characteristics {
+	c := SIZED | ORDERED | IMMUTABLE
+	if iter.start <= iter.end {
+		c |= SORTED
+	}
+	return c
+}
@@ -0,0 +1,87 @@
+//go:build go1.23
+
+package collections
+
+import (
+	"iter"
+
+	"github.com/go-park/stream/support/function"
+)
+
+type pullIterator[T any] struct {
+	next  func() (T, bool)
+	stop  func()
+	value T
+	ready bool
+	done  bool
+}
+
+// IterablePull adapts the next and stop functions of iter.Pull,
+// stop is called as soon as next reports the end.
+func IterablePull[T any](next func() (T, bool), stop func()) Iterator[T] {
+	return &pullIterator[T]{next: next, stop: stop}
+}
+
+// IterableSeq pulls the elements of seq one at a time.
+func IterableSeq[T any](seq iter.Seq[T]) Iterator[T] {
+	return IterablePull(iter.Pull(seq))
+}
+
+func (it *pullIterator[T]) HasNext() bool {
+	if it.ready {
+		return true
+	}
+	if it.done {
+		return false
+	}
+	v, ok := it.next()
+	if !ok {
+		it.done = true
+		if it.stop != nil {
+			it.stop()
+		}
+		return false
+	}
+	it.value, it.ready = v, true
+	return true
+}
+
+func (it *pullIterator[T]) Next() T {
+	var v T
+	if it.HasNext() {
+		v, it.value, it.ready = it.value, v, false
+	}
+	return v
+}
+
+func (it *pullIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for it.HasNext() {
+		fn(it.Next())
+	}
+}
+
+// Seq yields the remaining elements of it, breaking out of the loop leaves
+// the rest in it.
+func Seq[T any](it Iterator[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for it.HasNext() {
+			if !yield(it.Next()) {
+				return
+			}
+		}
+	}
+}
+
+// Pull is iter.Pull for an Iterator, which needs no goroutine since an
+// Iterator is pulled already. stop drops the remaining elements.
+func Pull[T any](it Iterator[T]) (next func() (T, bool), stop func()) {
+	stopped := false
+	next = func() (T, bool) {
+		var v T
+		if stopped || !it.HasNext() {
+			return v, false
+		}
+		return it.Next(), true
+	}
+	return next, func() { stopped = true }
+}
@@ -0,0 +1,41 @@
+//go:build go1.23
+
+package collections_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/go-park/stream/support/collections"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeq(t *testing.T) {
+	t.Run("iterable seq", func(t *testing.T) {
+		iter := collections.IterableSeq(slices.Values([]int{1, 2, 3}))
+		assert.True(t, iter.HasNext())
+		assert.Equal(t, 1, iter.Next())
+		var rest []int
+		iter.ForEachRemaining(func(v int) { rest = append(rest, v) })
+		assert.Equal(t, []int{2, 3}, rest)
+		assert.False(t, iter.HasNext())
+	})
+	t.Run("seq", func(t *testing.T) {
+		iter := collections.IterableSlice(1, 2, 3, 4)
+		for v := range collections.Seq(iter) {
+			if v == 2 {
+				break
+			}
+		}
+		assert.Equal(t, []int{3, 4}, slices.Collect(collections.Seq(iter)))
+	})
+	t.Run("pull", func(t *testing.T) {
+		next, stop := collections.Pull(collections.IterableSlice(1, 2, 3))
+		v, ok := next()
+		assert.Equal(t, 1, v)
+		assert.True(t, ok)
+		stop()
+		_, ok = next()
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,73 @@
+package collections
+
+import (
+	"strings"
+
+	"github.com/go-park/stream/support/function"
+)
+
+// Characteristics are facts a Spliterator guarantees about its elements,
+// a pipeline uses them to skip work it can prove unnecessary.
+type Characteristics uint
+
+const (
+	// SIZED means EstimateSize is the exact number of remaining elements.
+	SIZED Characteristics = 1 << iota
+	// ORDERED means the elements have a defined encounter order.
+	ORDERED
+	// SORTED means the elements come in ascending natural order.
+	SORTED
+	// DISTINCT means no two elements are equal.
+	DISTINCT
+	// IMMUTABLE means the element source cannot change while iterated.
+	IMMUTABLE
+)
+
+var characteristicNames = []string{"SIZED", "ORDERED", "SORTED", "DISTINCT", "IMMUTABLE"}
+
+func (c Characteristics) Has(flags Characteristics) bool {
+	return c&flags == flags
+}
+
+func (c Characteristics) String() string {
+	var names []string
+	for i, name := range characteristicNames {
+		if c.Has(1 << i) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Spliterator is an Iterator able to hand part of its elements over to
+// another spliterator, so they can be processed in parallel without copying.
+type Spliterator[T any] interface {
+	Iterator[T]
+	// TryAdvance passes the next element to fn, it returns false if there is none.
+	TryAdvance(fn function.Consumer[T]) bool
+	// TrySplit returns a spliterator covering a prefix of the remaining elements,
+	// which this one then skips, or nil if the elements cannot be split.
+	TrySplit() Spliterator[T]
+	// EstimateSize returns the number of remaining elements, exact if SIZED.
+	EstimateSize() int
+	Characteristics() Characteristics
+}
+
+// Truncater is implemented by spliterators able to drop every element past
+// their first n without iterating them.
+type Truncater interface {
+	Truncate(n int)
+}
+
+func tryAdvance[T any](iter Iterator[T], fn function.Consumer[T]) bool {
+	if !iter.HasNext() {
+		return false
+	}
+	fn(iter.Next())
+	return true
+}
+
+// IterableMap iterates over the entries of m, in no particular order.
+func IterableMap[M ~map[K]V, K comparable, V any](m M) Spliterator[Entry[K, V]] {
+	return &iterableSlice[Entry[K, V]]{slice: GetEntrySet(m), characteristics: SIZED | DISTINCT}
+}
@@ -0,0 +1,183 @@
+package collections
+
+import "github.com/go-park/stream/support/function"
+
+// visitSet remembers the nodes of a graph walk by key, a nil visitSet walks
+// a tree and visits every node it reaches.
+type visitSet[T any] interface {
+	visited(t T) bool
+	visit(t T)
+}
+
+type keySet[T any, K comparable] struct {
+	key  func(T) K
+	keys map[K]struct{}
+}
+
+func newKeySet[T any, K comparable](key func(T) K) *keySet[T, K] {
+	return &keySet[T, K]{key: key, keys: make(map[K]struct{})}
+}
+
+func (s *keySet[T, K]) visited(t T) bool {
+	_, ok := s.keys[s.key(t)]
+	return ok
+}
+
+func (s *keySet[T, K]) visit(t T) {
+	s.keys[s.key(t)] = struct{}{}
+}
+
+type bfsIterator[T any] struct {
+	queue    []T
+	children func(T) []T
+	seen     visitSet[T]
+}
+
+// WalkBFS visits root and its descendants level by level, children are
+// expanded only when their parent is returned.
+func WalkBFS[T any](root T, children func(T) []T) Iterator[T] {
+	return &bfsIterator[T]{queue: []T{root}, children: children}
+}
+
+// WalkGraphBFS is WalkBFS over a graph, a node is visited once whatever the
+// number of paths to it, the first found being the one kept.
+func WalkGraphBFS[T any, K comparable](root T, children func(T) []T, key func(T) K) Iterator[T] {
+	seen := newKeySet(key)
+	seen.visit(root)
+	return &bfsIterator[T]{queue: []T{root}, children: children, seen: seen}
+}
+
+func (iter *bfsIterator[T]) HasNext() bool {
+	return len(iter.queue) > 0
+}
+
+func (iter *bfsIterator[T]) Next() T {
+	var v T
+	if len(iter.queue) == 0 {
+		return v
+	}
+	v, iter.queue = iter.queue[0], iter.queue[1:]
+	for _, child := range iter.children(v) {
+		if iter.seen != nil {
+			if iter.seen.visited(child) {
+				continue
+			}
+			iter.seen.visit(child)
+		}
+		iter.queue = append(iter.queue, child)
+	}
+	return v
+}
+
+func (iter *bfsIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for iter.HasNext() {
+		fn(iter.Next())
+	}
+}
+
+type preOrderIterator[T any] struct {
+	stack    []T
+	children func(T) []T
+	seen     visitSet[T]
+}
+
+// WalkPreOrder visits a node before its children, depth first.
+func WalkPreOrder[T any](root T, children func(T) []T) Iterator[T] {
+	return &preOrderIterator[T]{stack: []T{root}, children: children}
+}
+
+// WalkGraphPreOrder is WalkPreOrder over a graph, a node is visited once
+// whatever the number of paths to it.
+func WalkGraphPreOrder[T any, K comparable](root T, children func(T) []T, key func(T) K) Iterator[T] {
+	return &preOrderIterator[T]{stack: []T{root}, children: children, seen: newKeySet(key)}
+}
+
+func (iter *preOrderIterator[T]) HasNext() bool {
+	// a node may be stacked again before its first visit, by another path
+	for iter.seen != nil && len(iter.stack) > 0 && iter.seen.visited(iter.stack[len(iter.stack)-1]) {
+		iter.stack = iter.stack[:len(iter.stack)-1]
+	}
+	return len(iter.stack) > 0
+}
+
+func (iter *preOrderIterator[T]) Next() T {
+	var v T
+	if !iter.HasNext() {
+		return v
+	}
+	v, iter.stack = iter.stack[len(iter.stack)-1], iter.stack[:len(iter.stack)-1]
+	if iter.seen != nil {
+		iter.seen.visit(v)
+	}
+	list := iter.children(v)
+	for i := len(list) - 1; i >= 0; i-- {
+		iter.stack = append(iter.stack, list[i])
+	}
+	return v
+}
+
+func (iter *preOrderIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for iter.HasNext() {
+		fn(iter.Next())
+	}
+}
+
+type frame[T any] struct {
+	node     T
+	children []T
+	expanded bool
+}
+
+type postOrderIterator[T any] struct {
+	stack    []*frame[T]
+	children func(T) []T
+	seen     visitSet[T]
+}
+
+// WalkPostOrder visits a node after all its children, depth first.
+func WalkPostOrder[T any](root T, children func(T) []T) Iterator[T] {
+	return &postOrderIterator[T]{stack: []*frame[T]{{node: root}}, children: children}
+}
+
+// WalkGraphPostOrder is WalkPostOrder over a graph, a node is visited once
+// whatever the number of paths to it. A child still being walked, on a
+// cycle, is skipped, so its parent may come first.
+func WalkGraphPostOrder[T any, K comparable](root T, children func(T) []T, key func(T) K) Iterator[T] {
+	seen := newKeySet(key)
+	seen.visit(root)
+	return &postOrderIterator[T]{stack: []*frame[T]{{node: root}}, children: children, seen: seen}
+}
+
+func (iter *postOrderIterator[T]) HasNext() bool {
+	return len(iter.stack) > 0
+}
+
+func (iter *postOrderIterator[T]) Next() T {
+	var v T
+	for len(iter.stack) > 0 {
+		top := iter.stack[len(iter.stack)-1]
+		if !top.expanded {
+			top.children, top.expanded = iter.children(top.node), true
+		}
+		if len(top.children) == 0 {
+			iter.stack = iter.stack[:len(iter.stack)-1]
+			return top.node
+		}
+		child := top.children[0]
+		top.children = top.children[1:]
+		if iter.seen != nil {
+			if iter.seen.visited(child) {
+				continue
+			}
+			iter.seen.visit(child)
+		}
+		iter.stack = append(iter.stack, &frame[T]{node: child})
+	}
+	return v
+}
+
+func (iter *postOrderIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for iter.HasNext() {
+		fn(iter.Next())
+	}
+}
@@ -0,0 +1,167 @@
+package stream
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-park/stream/internal/helper"
+	"github.com/go-park/stream/support/collections"
+	"github.com/go-park/stream/support/function"
+)
+
+var ErrCycle = errors.New("graph contains a cycle")
+
+type TraversalOrder int
+
+const (
+	PreOrder TraversalOrder = iota
+	PostOrder
+)
+
+// WalkBFS streams root and its descendants breadth first. The structure must
+// be a tree: nodes are not tracked, so a node reachable by several paths is
+// emitted once per path and a cycle makes the stream endless, use
+// WalkGraphBFS for graphs.
+func WalkBFS[T any](root T, children func(T) []T) Stream[T] {
+	helper.RequireCanButNonNil(children)
+	return Builder[T]().iterator(collections.WalkBFS(root, children)).Build()
+}
+
+// WalkDFS streams root and its descendants depth first in the given order.
+// Like WalkBFS it expects a tree, use WalkGraphDFS for graphs.
+func WalkDFS[T any](root T, children func(T) []T, order TraversalOrder) Stream[T] {
+	helper.RequireCanButNonNil(children)
+	if order == PostOrder {
+		return Builder[T]().iterator(collections.WalkPostOrder(root, children)).Build()
+	}
+	return Builder[T]().iterator(collections.WalkPreOrder(root, children)).Build()
+}
+
+// WalkGraphBFS streams the nodes reachable from root breadth first, each
+// once. Nodes are told apart by key, shared nodes and cycles are thus
+// walked through only once.
+func WalkGraphBFS[T any, K comparable](root T, children func(T) []T, key func(T) K) Stream[T] {
+	helper.RequireCanButNonNil(children)
+	helper.RequireCanButNonNil(key)
+	return Builder[T]().iterator(collections.WalkGraphBFS(root, children, key)).Build()
+}
+
+// WalkGraphDFS streams the nodes reachable from root depth first in the
+// given order, each once, telling nodes apart by key like WalkGraphBFS.
+func WalkGraphDFS[T any, K comparable](root T, children func(T) []T, key func(T) K, order TraversalOrder) Stream[T] {
+	helper.RequireCanButNonNil(children)
+	helper.RequireCanButNonNil(key)
+	if order == PostOrder {
+		return Builder[T]().iterator(collections.WalkGraphPostOrder(root, children, key)).Build()
+	}
+	return Builder[T]().iterator(collections.WalkGraphPreOrder(root, children, key)).Build()
+}
+
+// topoIterator runs Kahn's algorithm as it is iterated, the graph is built
+// on first use. The ready nodes are kept in a min heap of their position so
+// ties come out in node order.
+type topoIterator[T comparable] struct {
+	nodes   []T
+	edges   []collections.Pair[T, T]
+	all     []T
+	next    [][]int
+	degree  []int
+	ready   *sort.IntSlice
+	emitted int
+	err     error
+}
+
+func (it *topoIterator[T]) build() {
+	index := make(map[T]int, len(it.nodes))
+	add := func(n T) {
+		if _, ok := index[n]; !ok {
+			index[n] = len(it.all)
+			it.all = append(it.all, n)
+		}
+	}
+	for _, n := range it.nodes {
+		add(n)
+	}
+	for _, e := range it.edges {
+		add(e.Left())
+		add(e.Right())
+	}
+	it.next = make([][]int, len(it.all))
+	it.degree = make([]int, len(it.all))
+	for _, e := range it.edges {
+		from, to := index[e.Left()], index[e.Right()]
+		it.next[from] = append(it.next[from], to)
+		it.degree[to]++
+	}
+	it.ready = &sort.IntSlice{}
+	for i := range it.all {
+		if it.degree[i] == 0 {
+			heap.Push(intHeap{it.ready}, i)
+		}
+	}
+}
+
+func (it *topoIterator[T]) HasNext() bool {
+	if it.ready == nil {
+		it.build()
+	}
+	if it.ready.Len() > 0 {
+		return true
+	}
+	if it.err == nil && it.emitted < len(it.all) {
+		it.err = fmt.Errorf("%w: %d of %d nodes are unordered", ErrCycle, len(it.all)-it.emitted, len(it.all))
+	}
+	return false
+}
+
+func (it *topoIterator[T]) Next() T {
+	var v T
+	if !it.HasNext() {
+		return v
+	}
+	picked := heap.Pop(intHeap{it.ready}).(int)
+	it.emitted++
+	for _, to := range it.next[picked] {
+		it.degree[to]--
+		if it.degree[to] == 0 {
+			heap.Push(intHeap{it.ready}, to)
+		}
+	}
+	return it.all[picked]
+}
+
+func (it *topoIterator[T]) ForEachRemaining(fn function.Consumer[T]) {
+	for it.HasNext() {
+		fn(it.Next())
+	}
+}
+
+func (it *topoIterator[T]) Err() error {
+	return it.err
+}
+
+// TopologicalOrder streams nodes so that the left node of every edge comes
+// before its right node, ties keep the order of nodes. Nodes only found in
+// edges are appended to nodes. Nodes are ordered as the stream is consumed;
+// if no such order exists, the stream ends once no node is left free of
+// unordered predecessors and Err reports an error wrapping ErrCycle.
+func TopologicalOrder[T comparable](nodes []T, edges []collections.Pair[T, T]) Stream[T] {
+	return Builder[T]().iterator(&topoIterator[T]{nodes: nodes, edges: edges}).Build()
+}
+
+type intHeap struct {
+	*sort.IntSlice
+}
+
+func (h intHeap) Push(x any) {
+	*h.IntSlice = append(*h.IntSlice, x.(int))
+}
+
+func (h intHeap) Pop() any {
+	old := *h.IntSlice
+	x := old[len(old)-1]
+	*h.IntSlice = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,131 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/go-park/stream"
+	"github.com/go-park/stream/support/collections"
+	"github.com/stretchr/testify/assert"
+)
+
+type node struct {
+	Name     string
+	Children []*node
+}
+
+func TestTraversal(t *testing.T) {
+	//      a
+	//    /   \
+	//   b     c
+	//  / \     \
+	// d   e     f
+	tree := &node{Name: "a", Children: []*node{
+		{Name: "b", Children: []*node{{Name: "d"}, {Name: "e"}}},
+		{Name: "c", Children: []*node{{Name: "f"}}},
+	}}
+	expanded := 0
+	children := func(n *node) []*node {
+		expanded++
+		return n.Children
+	}
+	name := func(n *node) string { return n.Name }
+
+	t.Run("bfs", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, stream.ToList(stream.WalkBFS(tree, children), name))
+	})
+
+	t.Run("dfs", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b", "d", "e", "c", "f"},
+			stream.ToList(stream.WalkDFS(tree, children, stream.PreOrder), name))
+		assert.Equal(t, []string{"d", "e", "b", "f", "c", "a"},
+			stream.ToList(stream.WalkDFS(tree, children, stream.PostOrder), name))
+	})
+
+	t.Run("lazy", func(t *testing.T) {
+		expanded = 0
+		s := stream.WalkBFS(tree, children).Limit(2)
+		assert.Equal(t, []string{"a", "b"}, stream.ToList(s, name))
+		assert.Equal(t, 2, expanded)
+	})
+
+	t.Run("topological", func(t *testing.T) {
+		edges := []collections.Pair[string, string]{
+			collections.PairOf("core", "http"),
+			collections.PairOf("core", "db"),
+			collections.PairOf("db", "api"),
+			collections.PairOf("http", "api"),
+		}
+		s := stream.TopologicalOrder([]string{"api", "db", "http", "core", "cli"}, edges)
+		assert.Equal(t, []string{"core", "db", "http", "api", "cli"}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		edges := []collections.Pair[int, int]{
+			collections.PairOf(1, 2), collections.PairOf(2, 3), collections.PairOf(3, 1), collections.PairOf(0, 1),
+		}
+		s := stream.TopologicalOrder(nil, edges)
+		assert.Equal(t, []int{0}, s.ToSlice())
+		assert.ErrorIs(t, s.Err(), stream.ErrCycle)
+	})
+
+	t.Run("topological-limit", func(t *testing.T) {
+		edges := []collections.Pair[int, int]{collections.PairOf(2, 1), collections.PairOf(3, 1)}
+		assert.Equal(t, []int{2, 3}, stream.TopologicalOrder([]int{1, 2, 3}, edges).Limit(2).ToSlice())
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		// not a tree: d is reached through b and c
+		d := &node{Name: "d"}
+		dag := &node{Name: "a", Children: []*node{
+			{Name: "b", Children: []*node{d}},
+			{Name: "c", Children: []*node{d}},
+		}}
+		assert.Equal(t, []string{"a", "b", "c", "d", "d"}, stream.ToList(stream.WalkBFS(dag, children), name))
+		assert.Equal(t, []string{"a", "b", "d", "c", "d"},
+			stream.ToList(stream.WalkDFS(dag, children, stream.PreOrder), name))
+	})
+
+	t.Run("graph-diamond", func(t *testing.T) {
+		//   a
+		//  / \
+		// b   c
+		//  \ / \
+		//   d   e
+		d := &node{Name: "d"}
+		dag := &node{Name: "a", Children: []*node{
+			{Name: "b", Children: []*node{d}},
+			{Name: "c", Children: []*node{d, {Name: "e"}}},
+		}}
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"},
+			stream.ToList(stream.WalkGraphBFS(dag, children, name), name))
+		assert.Equal(t, []string{"a", "b", "d", "c", "e"},
+			stream.ToList(stream.WalkGraphDFS(dag, children, name, stream.PreOrder), name))
+		assert.Equal(t, []string{"d", "b", "e", "c", "a"},
+			stream.ToList(stream.WalkGraphDFS(dag, children, name, stream.PostOrder), name))
+	})
+
+	t.Run("graph-cycle", func(t *testing.T) {
+		// a -> b -> c -> a, c -> d
+		a := &node{Name: "a"}
+		c := &node{Name: "c", Children: []*node{a, {Name: "d"}}}
+		a.Children = []*node{{Name: "b", Children: []*node{c}}}
+		assert.Equal(t, []string{"a", "b", "c", "d"},
+			stream.ToList(stream.WalkGraphBFS(a, children, name), name))
+		assert.Equal(t, []string{"a", "b", "c", "d"},
+			stream.ToList(stream.WalkGraphDFS(a, children, name, stream.PreOrder), name))
+		assert.Equal(t, []string{"d", "c", "b", "a"},
+			stream.ToList(stream.WalkGraphDFS(a, children, name, stream.PostOrder), name))
+		// a node pointing to itself
+		self := &node{Name: "self"}
+		self.Children = []*node{self}
+		assert.Equal(t, 1, stream.WalkGraphBFS(self, children, name).Count())
+	})
+
+	t.Run("cyclic", func(t *testing.T) {
+		a := &node{Name: "a"}
+		a.Children = []*node{{Name: "b", Children: []*node{a}}}
+		assert.Equal(t, []string{"a", "b", "a", "b", "a"},
+			stream.ToList(stream.WalkBFS(a, children).Limit(5), name))
+	})
+}